@@ -25,30 +25,31 @@ const (
 )
 
 type Scenario struct {
-	ID                           int     `json:"id"`
-	AccountID                    int     `json:"account_id"`
-	Title                        string  `json:"title"`
-	Description                  string  `json:"description"`
-	InterestRate                 float64 `json:"interest_rate"`
-	InterestRateRepeatID         int     `json:"interest_rate_repeat_id"`
-	Type                         string  `json:"type"`
-	IsNetWorth                   bool    `json:"is_net_worth"`
-	MinimumValue                 float64 `json:"minimum_value"`
-	MaximumValue                 float64 `json:"maximum_value"`
-	AchieveDate                  string  `json:"achieve_date"`
-	StartingBalance              float64 `json:"starting_balance"`
-	StartingBalanceDate          string  `json:"starting_balance_date"`
-	ClosingBalance               float64 `json:"closing_balance"`
-	ClosingBalanceDate           string  `json:"closing_balance_date"`
-	CurrentBalance               float64 `json:"current_balance"`
-	CurrentBalanceDate           string  `json:"current_balance_date"`
-	CurrentBalanceInBaseCurrency float64 `json:"current_balance_in_base_currency"`
-	CurrentBalanceExchangeRate   float64 `json:"current_balance_exchange_rate"`
-	SafeBalance                  float64 `json:"safe_balance"`
-	SafeBalanceInBaseCurrency    float64 `json:"safe_balance_in_base_currency"`
-	HasSafeBalanceAdjustment     bool    `json:"has_safe_balance_adjustment"`
-	CreatedAt                    string  `json:"created_at"`
-	UpdatedAt                    string  `json:"updated_at"`
+	ID                           int                        `json:"id"`
+	AccountID                    int                        `json:"account_id"`
+	Title                        string                     `json:"title"`
+	Description                  string                     `json:"description"`
+	InterestRate                 float64                    `json:"interest_rate"`
+	InterestRateRepeatID         int                        `json:"interest_rate_repeat_id"`
+	Type                         string                     `json:"type"`
+	IsNetWorth                   bool                       `json:"is_net_worth"`
+	MinimumValue                 float64                    `json:"minimum_value"`
+	MaximumValue                 float64                    `json:"maximum_value"`
+	AchieveDate                  string                     `json:"achieve_date"`
+	StartingBalance              float64                    `json:"starting_balance"`
+	StartingBalanceDate          string                     `json:"starting_balance_date"`
+	ClosingBalance               float64                    `json:"closing_balance"`
+	ClosingBalanceDate           string                     `json:"closing_balance_date"`
+	CurrentBalance               float64                    `json:"current_balance"`
+	CurrentBalanceDate           string                     `json:"current_balance_date"`
+	CurrentBalanceInBaseCurrency float64                    `json:"current_balance_in_base_currency"`
+	CurrentBalanceExchangeRate   float64                    `json:"current_balance_exchange_rate"`
+	SafeBalance                  float64                    `json:"safe_balance"`
+	SafeBalanceInBaseCurrency    float64                    `json:"safe_balance_in_base_currency"`
+	HasSafeBalanceAdjustment     bool                       `json:"has_safe_balance_adjustment"`
+	CreatedAt                    string                     `json:"created_at"`
+	UpdatedAt                    string                     `json:"updated_at"`
+	Extra                        map[string]json.RawMessage `json:"-"`
 }
 
 type TransactionAccount struct {
@@ -57,51 +58,53 @@ type TransactionAccount struct {
 	Name      string `json:"name"`
 	// Number is read-only. It is returned by the API but is not accepted by
 	// PUT /transaction_accounts/{id}.
-	Number                       string      `json:"number"`
-	LatestFeedName               string      `json:"latest_feed_name"`
-	Offline                      bool        `json:"offline"`
-	IsNetWorth                   bool        `json:"is_net_worth"`
-	IncludeInNetWorth            bool        `json:"include_in_net_worth"`
-	CurrentBalance               float64     `json:"current_balance"`
-	CurrentBalanceDate           string      `json:"current_balance_date"`
-	CurrentBalanceInBaseCurrency float64     `json:"current_balance_in_base_currency"`
-	CurrentBalanceExchangeRate   float64     `json:"current_balance_exchange_rate"`
-	CurrentBalanceSource         string      `json:"current_balance_source"`
-	DataFeedsBalanceType         string      `json:"data_feeds_balance_type"`
-	DataFeedsAccountID           string      `json:"data_feeds_account_id"`
-	DataFeedsConnectionID        string      `json:"data_feeds_connection_id"`
-	SafeBalance                  float64     `json:"safe_balance"`
-	SafeBalanceInBaseCurrency    float64     `json:"safe_balance_in_base_currency"`
-	HasSafeBalanceAdjustment     bool        `json:"has_safe_balance_adjustment"`
-	StartingBalance              float64     `json:"starting_balance"`
-	StartingBalanceDate          string      `json:"starting_balance_date"`
-	CreatedAt                    string      `json:"created_at"`
-	UpdatedAt                    string      `json:"updated_at"`
-	Institution                  Institution `json:"institution"`
-	CurrencyCode                 string      `json:"currency_code"`
-	Type                         AccountType `json:"type"`
+	Number                       string                     `json:"number"`
+	LatestFeedName               string                     `json:"latest_feed_name"`
+	Offline                      bool                       `json:"offline"`
+	IsNetWorth                   bool                       `json:"is_net_worth"`
+	IncludeInNetWorth            bool                       `json:"include_in_net_worth"`
+	CurrentBalance               float64                    `json:"current_balance"`
+	CurrentBalanceDate           string                     `json:"current_balance_date"`
+	CurrentBalanceInBaseCurrency float64                    `json:"current_balance_in_base_currency"`
+	CurrentBalanceExchangeRate   float64                    `json:"current_balance_exchange_rate"`
+	CurrentBalanceSource         string                     `json:"current_balance_source"`
+	DataFeedsBalanceType         string                     `json:"data_feeds_balance_type"`
+	DataFeedsAccountID           string                     `json:"data_feeds_account_id"`
+	DataFeedsConnectionID        string                     `json:"data_feeds_connection_id"`
+	SafeBalance                  float64                    `json:"safe_balance"`
+	SafeBalanceInBaseCurrency    float64                    `json:"safe_balance_in_base_currency"`
+	HasSafeBalanceAdjustment     bool                       `json:"has_safe_balance_adjustment"`
+	StartingBalance              float64                    `json:"starting_balance"`
+	StartingBalanceDate          string                     `json:"starting_balance_date"`
+	CreatedAt                    string                     `json:"created_at"`
+	UpdatedAt                    string                     `json:"updated_at"`
+	Institution                  Institution                `json:"institution"`
+	CurrencyCode                 string                     `json:"currency_code"`
+	Type                         AccountType                `json:"type"`
+	Extra                        map[string]json.RawMessage `json:"-"`
 }
 
 type Account struct {
-	ID                           int                  `json:"id"`
-	Title                        string               `json:"title"`
-	CurrencyCode                 string               `json:"currency_code"`
-	Type                         AccountType          `json:"type"`
-	IsNetWorth                   bool                 `json:"is_net_worth"`
-	IncludeInNetWorth            bool                 `json:"include_in_net_worth"`
-	PrimaryTransactionAccount    TransactionAccount   `json:"primary_transaction_account"`
-	PrimaryScenario              Scenario             `json:"primary_scenario"`
-	TransactionAccounts          []TransactionAccount `json:"transaction_accounts"`
-	Scenarios                    []Scenario           `json:"scenarios"`
-	CreatedAt                    string               `json:"created_at"`
-	UpdatedAt                    string               `json:"updated_at"`
-	CurrentBalance               float64              `json:"current_balance"`
-	CurrentBalanceDate           string               `json:"current_balance_date"`
-	CurrentBalanceInBaseCurrency float64              `json:"current_balance_in_base_currency"`
-	CurrentBalanceExchangeRate   float64              `json:"current_balance_exchange_rate"`
-	SafeBalance                  float64              `json:"safe_balance"`
-	SafeBalanceInBaseCurrency    float64              `json:"safe_balance_in_base_currency"`
-	HasSafeBalanceAdjustment     bool                 `json:"has_safe_balance_adjustment"`
+	ID                           int                        `json:"id"`
+	Title                        string                     `json:"title"`
+	CurrencyCode                 string                     `json:"currency_code"`
+	Type                         AccountType                `json:"type"`
+	IsNetWorth                   bool                       `json:"is_net_worth"`
+	IncludeInNetWorth            bool                       `json:"include_in_net_worth"`
+	PrimaryTransactionAccount    TransactionAccount         `json:"primary_transaction_account"`
+	PrimaryScenario              Scenario                   `json:"primary_scenario"`
+	TransactionAccounts          []TransactionAccount       `json:"transaction_accounts"`
+	Scenarios                    []Scenario                 `json:"scenarios"`
+	CreatedAt                    string                     `json:"created_at"`
+	UpdatedAt                    string                     `json:"updated_at"`
+	CurrentBalance               float64                    `json:"current_balance"`
+	CurrentBalanceDate           string                     `json:"current_balance_date"`
+	CurrentBalanceInBaseCurrency float64                    `json:"current_balance_in_base_currency"`
+	CurrentBalanceExchangeRate   float64                    `json:"current_balance_exchange_rate"`
+	SafeBalance                  float64                    `json:"safe_balance"`
+	SafeBalanceInBaseCurrency    float64                    `json:"safe_balance_in_base_currency"`
+	HasSafeBalanceAdjustment     bool                       `json:"has_safe_balance_adjustment"`
+	Extra                        map[string]json.RawMessage `json:"-"`
 }
 
 func (c *Client) ListAccounts(userID int) ([]*Account, error) {
@@ -0,0 +1,224 @@
+// Package analytics totals income and spending by category, label and payee
+// over weeks, months and years, following the user's calendar settings.
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// GroupBy selects what transactions are grouped by within a period.
+type GroupBy string
+
+const (
+	ByCategory GroupBy = "category"
+	ByLabel    GroupBy = "label"
+	ByPayee    GroupBy = "payee"
+	// ByNone totals each period without grouping.
+	ByNone GroupBy = "none"
+)
+
+// Row is the total of one group in one period. Amounts are in the base
+// currency; Expense is positive.
+type Row struct {
+	PeriodStart string  `json:"period_start"`
+	PeriodEnd   string  `json:"period_end"`
+	Group       string  `json:"group"`
+	CategoryID  int     `json:"category_id,omitempty"`
+	Income      float64 `json:"income"`
+	Expense     float64 `json:"expense"`
+	Net         float64 `json:"net"`
+	Count       int     `json:"count"`
+}
+
+// Aggregator totals transactions.
+type Aggregator struct {
+	Calendar   Calendar
+	categories map[int]*pocketsmith.Category
+}
+
+// NewAggregator returns an Aggregator using the user's calendar. categories
+// is the user's category tree, as returned by ListCategories; it is used to
+// roll sub-categories up into parents that have RollUp set.
+func NewAggregator(user *pocketsmith.User, categories []*pocketsmith.Category) *Aggregator {
+	a := &Aggregator{Calendar: CalendarFor(user), categories: make(map[int]*pocketsmith.Category)}
+
+	var walk func(parentID int, categories []*pocketsmith.Category)
+	walk = func(parentID int, categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			c := *category
+			if c.ParentID == 0 {
+				c.ParentID = parentID
+			}
+			a.categories[c.ID] = &c
+			walk(c.ID, category.Children)
+		}
+	}
+	walk(0, categories)
+
+	return a
+}
+
+// Aggregate totals the transactions per period and group, skipping
+// transfers. Transactions with several labels count towards each of them.
+// Rows are sorted by period and then by group.
+func (a *Aggregator) Aggregate(transactions []*pocketsmith.DetailedTransaction, period Period, groupBy GroupBy) ([]*Row, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		start string
+		group string
+		id    int
+	}
+	rows := make(map[key]*Row)
+
+	for _, tx := range transactions {
+		if tx.IsTransfer || (tx.Category != nil && tx.Category.IsTransfer) {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: invalid date %q", tx.ID, tx.Date)
+		}
+
+		var start, end string
+		if period != All {
+			s, e := a.Calendar.Bounds(date, period)
+			start, end = s.Format("2006-01-02"), e.Format("2006-01-02")
+		}
+
+		for _, group := range a.groups(tx, groupBy) {
+			k := key{start, group.name, group.id}
+			row, ok := rows[k]
+			if !ok {
+				row = &Row{PeriodStart: start, PeriodEnd: end, Group: group.name, CategoryID: group.id}
+				rows[k] = row
+			}
+
+			amount := tx.AmountInBaseCurrency
+			if amount == 0 {
+				amount = tx.Amount
+			}
+			if amount > 0 {
+				row.Income += amount
+			} else {
+				row.Expense -= amount
+			}
+			row.Net += amount
+			row.Count++
+		}
+	}
+
+	sorted := make([]*Row, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, row)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].PeriodStart != sorted[j].PeriodStart {
+			return sorted[i].PeriodStart < sorted[j].PeriodStart
+		}
+		return sorted[i].Group < sorted[j].Group
+	})
+
+	return sorted, nil
+}
+
+type group struct {
+	name string
+	id   int
+}
+
+func (a *Aggregator) groups(tx *pocketsmith.DetailedTransaction, groupBy GroupBy) []group {
+	switch groupBy {
+	case ByCategory:
+		category := a.rollUp(tx.Category)
+		if category == nil {
+			return []group{{name: "Uncategorised"}}
+		}
+		return []group{{name: category.Title, id: category.ID}}
+	case ByLabel:
+		if len(tx.Labels) == 0 {
+			return []group{{name: ""}}
+		}
+		groups := make([]group, 0, len(tx.Labels))
+		for _, label := range tx.Labels {
+			groups = append(groups, group{name: label})
+		}
+		return groups
+	case ByPayee:
+		return []group{{name: tx.Payee}}
+	}
+	return []group{{}}
+}
+
+// rollUp returns the category a transaction's totals are reported under:
+// the category itself, or its nearest ancestor with RollUp set whose
+// ancestors don't roll up further.
+func (a *Aggregator) rollUp(category *pocketsmith.Category) *pocketsmith.Category {
+	if category == nil {
+		return nil
+	}
+
+	current, ok := a.categories[category.ID]
+	if !ok {
+		return category
+	}
+
+	result := current
+	for seen := 0; current.ParentID != 0 && seen < len(a.categories); seen++ {
+		parent, ok := a.categories[current.ParentID]
+		if !ok {
+			break
+		}
+		if parent.RollUp {
+			result = parent
+		}
+		current = parent
+	}
+
+	return result
+}
+
+func checkPeriod(period Period) error {
+	switch period {
+	case Week, Month, Year, All:
+		return nil
+	}
+	return fmt.Errorf("unknown period %q", period)
+}
+
+// Build fetches the user, their categories and their transactions and
+// aggregates them. opts are passed to ListTransactionsInUser, for example to
+// limit the date range.
+func Build(client *pocketsmith.Client, userID int, period Period, groupBy GroupBy, opts ...pocketsmith.ListTransactionsOption) ([]*Row, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+
+	user, err := client.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	categories, err := client.ListCategories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	var transactions []*pocketsmith.DetailedTransaction
+	err = client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		transactions = append(transactions, page...)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return NewAggregator(user, categories).Aggregate(transactions, period, groupBy)
+}
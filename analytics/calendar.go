@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Period is the length of the periods transactions are totalled over.
+type Period string
+
+const (
+	Week  Period = "week"
+	Month Period = "month"
+	Year  Period = "year"
+	// All puts every transaction into a single period.
+	All Period = "all"
+)
+
+// Calendar holds a user's preferences for where weeks, months and years
+// start.
+type Calendar struct {
+	// WeekStartDay is the first day of the week, 0 for Sunday.
+	WeekStartDay int
+	// MonthStartsOnDay is the day of the month a month starts on, for example
+	// 15 for users paid mid-month. Months shorter than this start on their
+	// last day.
+	MonthStartsOnDay int
+	// YearStartsOnMonth is the month a year starts in, 1 for January.
+	YearStartsOnMonth int
+}
+
+// CalendarFor returns the user's calendar, defaulting to weeks starting on
+// Sunday and months and years starting on the 1st of January.
+func CalendarFor(user *pocketsmith.User) Calendar {
+	c := Calendar{
+		WeekStartDay:      user.WeekStartDay,
+		MonthStartsOnDay:  user.MonthStartsOnDay,
+		YearStartsOnMonth: user.YearStartsOnMonth,
+	}
+	if c.WeekStartDay < 0 || c.WeekStartDay > 6 {
+		c.WeekStartDay = 0
+	}
+	if c.MonthStartsOnDay < 1 {
+		c.MonthStartsOnDay = 1
+	}
+	if c.YearStartsOnMonth < 1 || c.YearStartsOnMonth > 12 {
+		c.YearStartsOnMonth = 1
+	}
+	return c
+}
+
+// Bounds returns the first and last day of the period containing date. It
+// returns zero times for All and unknown periods.
+func (c Calendar) Bounds(date time.Time, period Period) (time.Time, time.Time) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case Week:
+		offset := (int(date.Weekday()) - c.WeekStartDay + 7) % 7
+		start := date.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 6)
+	case Month:
+		start := c.monthStart(date.Year(), date.Month())
+		if date.Before(start) {
+			start = c.monthStart(date.Year(), date.Month()-1)
+		}
+		next := c.monthStart(start.Year(), start.Month()+1)
+		return start, next.AddDate(0, 0, -1)
+	case Year:
+		yearMonth := time.Month(c.YearStartsOnMonth)
+		if yearMonth == 0 {
+			yearMonth = time.January
+		}
+		start := c.monthStart(date.Year(), yearMonth)
+		if date.Before(start) {
+			start = c.monthStart(date.Year()-1, yearMonth)
+		}
+		next := c.monthStart(start.Year()+1, yearMonth)
+		return start, next.AddDate(0, 0, -1)
+	}
+
+	return time.Time{}, time.Time{}
+}
+
+// monthStart returns the day the month starting in year and month begins on.
+// month may be out of range and is normalised.
+func (c Calendar) monthStart(year int, month time.Month) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	day := c.MonthStartsOnDay
+	if day < 1 {
+		day = 1
+	}
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -2,64 +2,71 @@ package pocketsmith
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 )
 
 type ContentTypeMeta struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Extension   string `json:"extension"`
+	Title       string                     `json:"title"`
+	Description string                     `json:"description"`
+	Extension   string                     `json:"extension"`
+	Extra       map[string]json.RawMessage `json:"-"`
 }
 
 type AttachmentVariants struct {
-	ThumbURL string `json:"thumb_url"`
-	LargeURL string `json:"large_url"`
+	ThumbURL string                     `json:"thumb_url"`
+	LargeURL string                     `json:"large_url"`
+	Extra    map[string]json.RawMessage `json:"-"`
 }
 
 // AttachableCategory is the abbreviated category returned inside an
 // attachment's attachables list.
 type AttachableCategory struct {
-	ID     int    `json:"id"`
-	Title  string `json:"title"`
-	Colour string `json:"colour"`
-	Icon   string `json:"icon"`
+	ID     int                        `json:"id"`
+	Title  string                     `json:"title"`
+	Colour string                     `json:"colour"`
+	Icon   string                     `json:"icon"`
+	Extra  map[string]json.RawMessage `json:"-"`
 }
 
 // Attachable is a record an attachment is assigned to.
 type Attachable struct {
-	Type                 string              `json:"type"`
-	ID                   int64               `json:"id"`
-	Date                 string              `json:"date"`
-	Payee                string              `json:"payee"`
-	Amount               float64             `json:"amount"`
-	AmountInBaseCurrency float64             `json:"amount_in_base_currency"`
-	Category             *AttachableCategory `json:"category"`
+	Type                 string                     `json:"type"`
+	ID                   int64                      `json:"id"`
+	Date                 string                     `json:"date"`
+	Payee                string                     `json:"payee"`
+	Amount               float64                    `json:"amount"`
+	AmountInBaseCurrency float64                    `json:"amount_in_base_currency"`
+	Category             *AttachableCategory        `json:"category"`
+	Extra                map[string]json.RawMessage `json:"-"`
 }
 
 type Attachment struct {
-	ID              int64                `json:"id"`
-	Title           string               `json:"title"`
-	FileName        string               `json:"file_name"`
-	FileSize        int64                `json:"file_size"`
-	Type            string               `json:"type"`
-	ContentType     string               `json:"content_type"`
-	ContentTypeMeta ContentTypeMeta      `json:"content_type_meta"`
-	OriginalURL     string               `json:"original_url"`
-	Variants        AttachmentVariants   `json:"variants"`
-	UploadSource    string               `json:"upload_source"`
-	Description     string               `json:"description"`
-	Starred         bool                 `json:"starred"`
-	Important       bool                 `json:"important"`
-	Assigned        bool                 `json:"assigned"`
-	AttachedTo      *DetailedTransaction `json:"attached_to"`
-	AttachedType    string               `json:"attached_type"`
-	AttachedDate    string               `json:"attached_date"`
-	Attachables     []*Attachable        `json:"attachables"`
-	TagNames        []string             `json:"tag_names"`
-	CreatedAt       string               `json:"created_at"`
-	UpdatedAt       string               `json:"updated_at"`
+	ID              int64                      `json:"id"`
+	Title           string                     `json:"title"`
+	FileName        string                     `json:"file_name"`
+	FileSize        int64                      `json:"file_size"`
+	Type            string                     `json:"type"`
+	ContentType     string                     `json:"content_type"`
+	ContentTypeMeta ContentTypeMeta            `json:"content_type_meta"`
+	OriginalURL     string                     `json:"original_url"`
+	Variants        AttachmentVariants         `json:"variants"`
+	UploadSource    string                     `json:"upload_source"`
+	Description     string                     `json:"description"`
+	Starred         bool                       `json:"starred"`
+	Important       bool                       `json:"important"`
+	Assigned        bool                       `json:"assigned"`
+	AttachedTo      *DetailedTransaction       `json:"attached_to"`
+	AttachedType    string                     `json:"attached_type"`
+	AttachedDate    string                     `json:"attached_date"`
+	Attachables     []*Attachable              `json:"attachables"`
+	TagNames        []string                   `json:"tag_names"`
+	CreatedAt       string                     `json:"created_at"`
+	UpdatedAt       string                     `json:"updated_at"`
+	Extra           map[string]json.RawMessage `json:"-"`
 }
 
 // ListAttachments retrieves all attachments for a given user
@@ -215,3 +222,30 @@ func (c *Client) UpdateAttachment(attachmentID int64, update *UpdateAttachment)
 
 	return &updatedAttachment, nil
 }
+
+// DownloadAttachment writes the attachment's original file to w. The file is
+// fetched with the client's http.Client, so its timeout, transport and hooks
+// apply, but without the developer key, which the file host doesn't need.
+func (c *Client) DownloadAttachment(ctx context.Context, attachment *Attachment, w io.Writer) error {
+	if attachment.OriginalURL == "" {
+		return fmt.Errorf("attachment %d has no file", attachment.ID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", attachment.OriginalURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,217 @@
+// Package backup snapshots a PocketSmith user's data into an archive
+// directory and restores it into another user.
+//
+// An archive is a directory holding a manifest.json, one JSON file per
+// resource type, transactions.jsonl with one transaction per line, and an
+// attachments directory with the attachment files.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/export"
+)
+
+// FormatVersion is the archive layout version written to the manifest.
+// Restore refuses archives with a newer version.
+const FormatVersion = 1
+
+const (
+	manifestFile            = "manifest.json"
+	userFile                = "user.json"
+	institutionsFile        = "institutions.json"
+	accountsFile            = "accounts.json"
+	transactionAccountsFile = "transaction_accounts.json"
+	categoriesFile          = "categories.json"
+	categoryRulesFile       = "category_rules.json"
+	labelsFile              = "labels.json"
+	savedSearchesFile       = "saved_searches.json"
+	transactionsFile        = "transactions.jsonl"
+	attachmentsFile         = "attachments.json"
+	attachmentsDir          = "attachments"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Version   int    `json:"version"`
+	UserID    int    `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+	// Transactions and Attachments are the number of records written, used to
+	// check an archive is complete.
+	Transactions int `json:"transactions"`
+	Attachments  int `json:"attachments"`
+}
+
+// Backup writes a snapshot of the user's data to dir, which is created if it
+// doesn't exist. Attachment files are downloaded from their original URL.
+func Backup(client *pocketsmith.Client, userID int, dir string) (*Manifest, error) {
+	if err := os.MkdirAll(filepath.Join(dir, attachmentsDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	user, err := client.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+	if err := writeJSON(dir, userFile, user); err != nil {
+		return nil, err
+	}
+
+	snapshots := []struct {
+		file string
+		list func() (any, error)
+	}{
+		{institutionsFile, func() (any, error) { return client.ListInstitutions(userID) }},
+		{accountsFile, func() (any, error) { return client.ListAccounts(userID) }},
+		{transactionAccountsFile, func() (any, error) { return client.ListTransactionAccounts(userID) }},
+		{categoriesFile, func() (any, error) { return client.ListCategories(userID) }},
+		{categoryRulesFile, func() (any, error) { return client.ListCategoryRules(userID) }},
+		{labelsFile, func() (any, error) { return client.ListLabels(userID) }},
+		{savedSearchesFile, func() (any, error) { return client.ListSavedSearches(userID) }},
+	}
+	for _, snapshot := range snapshots {
+		records, err := snapshot.list()
+		if err != nil {
+			return nil, fmt.Errorf("error backing up %s: %w", snapshot.file, err)
+		}
+		if err := writeJSON(dir, snapshot.file, records); err != nil {
+			return nil, err
+		}
+	}
+
+	if manifest.Transactions, err = backupTransactions(client, userID, dir); err != nil {
+		return nil, err
+	}
+
+	if manifest.Attachments, err = backupAttachments(client, userID, dir); err != nil {
+		return nil, err
+	}
+
+	if err := writeJSON(dir, manifestFile, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ReadManifest reads and checks the manifest of an archive.
+func ReadManifest(dir string) (*Manifest, error) {
+	var manifest Manifest
+	if err := readJSON(dir, manifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("archive version %d is newer than supported version %d", manifest.Version, FormatVersion)
+	}
+	return &manifest, nil
+}
+
+func backupTransactions(client *pocketsmith.Client, userID int, dir string) (int, error) {
+	f, err := os.Create(filepath.Join(dir, transactionsFile))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	w := export.NewJSONLinesWriter(f)
+	err = client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		for _, tx := range page {
+			if err := w.Write(tx); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error backing up transactions: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	return count, f.Close()
+}
+
+func backupAttachments(client *pocketsmith.Client, userID int, dir string) (int, error) {
+	attachments, err := client.ListAttachments(userID, false)
+	if err != nil {
+		return 0, fmt.Errorf("error listing attachments: %w", err)
+	}
+
+	for _, attachment := range attachments {
+		if attachment.OriginalURL == "" {
+			continue
+		}
+		if err := download(client, attachment, filepath.Join(dir, attachmentsDir, attachmentFileName(attachment))); err != nil {
+			return 0, fmt.Errorf("error downloading attachment %d: %w", attachment.ID, err)
+		}
+	}
+
+	if err := writeJSON(dir, attachmentsFile, attachments); err != nil {
+		return 0, err
+	}
+
+	return len(attachments), nil
+}
+
+// attachmentFileName is the name an attachment's file is stored under in the
+// archive. It is prefixed with the ID since file names aren't unique.
+func attachmentFileName(attachment *pocketsmith.Attachment) string {
+	name := filepath.Base(attachment.FileName)
+	if name == "." || name == string(filepath.Separator) {
+		name = "file"
+	}
+	return fmt.Sprintf("%d-%s", attachment.ID, strings.ReplaceAll(name, string(filepath.Separator), "_"))
+}
+
+// DownloadTimeout limits how long downloading a single attachment may take.
+const DownloadTimeout = 5 * time.Minute
+
+func download(client *pocketsmith.Client, attachment *pocketsmith.Attachment, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DownloadTimeout)
+	defer cancel()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := client.DownloadAttachment(ctx, attachment, f); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeJSON(dir, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}
+
+func readJSON(dir, name string, v any) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
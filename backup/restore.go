@@ -0,0 +1,349 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// IDMap maps IDs in the archive to the IDs of the records created for them
+// in the target user.
+type IDMap struct {
+	Institutions        map[int]int
+	Accounts            map[int]int
+	TransactionAccounts map[int]int
+	Categories          map[int]int
+	Transactions        map[int64]int64
+	Attachments         map[int64]int64
+}
+
+func newIDMap() *IDMap {
+	return &IDMap{
+		Institutions:        make(map[int]int),
+		Accounts:            make(map[int]int),
+		TransactionAccounts: make(map[int]int),
+		Categories:          make(map[int]int),
+		Transactions:        make(map[int64]int64),
+		Attachments:         make(map[int64]int64),
+	}
+}
+
+// Restore recreates the archive in dir into the target user and returns the
+// mapping from archived IDs to new IDs.
+//
+// Institutions and categories that already exist in the target user with the
+// same title (and, for categories, the same parent) are reused rather than
+// duplicated. The API creates every account with a single transaction
+// account and can't add more, so archives with an account that had several
+// transaction accounts are refused before anything is restored, rather than
+// merging their ledgers. Labels are restored through the
+// transactions that use them. Saved searches are kept in the archive but
+// can't be restored, since the API doesn't allow creating them.
+//
+// Restore is not idempotent: running it twice creates every account,
+// transaction and attachment twice.
+func Restore(client *pocketsmith.Client, dir string, userID int) (*IDMap, error) {
+	if _, err := ReadManifest(dir); err != nil {
+		return nil, err
+	}
+
+	if err := checkTransactionAccounts(dir); err != nil {
+		return nil, err
+	}
+
+	ids := newIDMap()
+
+	if err := restoreInstitutions(client, dir, userID, ids); err != nil {
+		return ids, err
+	}
+	if err := restoreAccounts(client, dir, userID, ids); err != nil {
+		return ids, err
+	}
+	if err := restoreCategories(client, dir, userID, ids); err != nil {
+		return ids, err
+	}
+	if err := restoreCategoryRules(client, dir, userID, ids); err != nil {
+		return ids, err
+	}
+	if err := restoreTransactions(client, dir, ids); err != nil {
+		return ids, err
+	}
+	if err := restoreAttachments(client, dir, userID, ids); err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+func restoreInstitutions(client *pocketsmith.Client, dir string, userID int, ids *IDMap) error {
+	var institutions []*pocketsmith.Institution
+	if err := readJSON(dir, institutionsFile, &institutions); err != nil {
+		return err
+	}
+
+	for _, institution := range institutions {
+		existing, err := client.FindInstitutionByName(userID, institution.Title)
+		if errors.Is(err, pocketsmith.ErrNotFound) {
+			existing, err = client.CreateInstitution(userID, institution.Title, institution.CurrencyCode)
+		}
+		if err != nil {
+			return fmt.Errorf("error restoring institution %q: %w", institution.Title, err)
+		}
+
+		ids.Institutions[institution.ID] = existing.ID
+	}
+
+	return nil
+}
+
+// checkTransactionAccounts returns an error if an archived account has a
+// transaction account besides its primary one, which Restore can't recreate.
+func checkTransactionAccounts(dir string) error {
+	var accounts []*pocketsmith.Account
+	if err := readJSON(dir, accountsFile, &accounts); err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		var extra []string
+		for _, transactionAccount := range account.TransactionAccounts {
+			if transactionAccount.ID != account.PrimaryTransactionAccount.ID {
+				extra = append(extra, fmt.Sprintf("%q (%d)", transactionAccount.Name, transactionAccount.ID))
+			}
+		}
+		if len(extra) > 0 {
+			return fmt.Errorf("account %q has transaction accounts besides its primary one, which can't be restored: %s", account.Title, strings.Join(extra, ", "))
+		}
+	}
+
+	return nil
+}
+
+func restoreAccounts(client *pocketsmith.Client, dir string, userID int, ids *IDMap) error {
+	var accounts []*pocketsmith.Account
+	if err := readJSON(dir, accountsFile, &accounts); err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		primary := account.PrimaryTransactionAccount
+		institutionID, ok := ids.Institutions[primary.Institution.ID]
+		if !ok {
+			return fmt.Errorf("account %q refers to unknown institution %d", account.Title, primary.Institution.ID)
+		}
+
+		created, err := client.CreateAccount(userID, institutionID, account.Title, account.CurrencyCode, account.Type)
+		if err != nil {
+			return fmt.Errorf("error restoring account %q: %w", account.Title, err)
+		}
+		ids.Accounts[account.ID] = created.ID
+
+		newTransactionAccountID := created.PrimaryTransactionAccount.ID
+		if newTransactionAccountID == 0 && len(created.TransactionAccounts) > 0 {
+			newTransactionAccountID = created.TransactionAccounts[0].ID
+		}
+
+		ids.TransactionAccounts[primary.ID] = newTransactionAccountID
+
+		if primary.StartingBalanceDate != "" {
+			_, err := client.UpdateTransactionAccount(newTransactionAccountID, institutionID, primary.StartingBalance, primary.StartingBalanceDate)
+			if err != nil {
+				return fmt.Errorf("error restoring starting balance of %q: %w", account.Title, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func restoreCategories(client *pocketsmith.Client, dir string, userID int, ids *IDMap) error {
+	var categories []*pocketsmith.Category
+	if err := readJSON(dir, categoriesFile, &categories); err != nil {
+		return err
+	}
+
+	existing, err := client.ListCategories(userID)
+	if err != nil {
+		return fmt.Errorf("error listing categories: %w", err)
+	}
+
+	type key struct {
+		parentID int
+		title    string
+	}
+	byTitle := make(map[key]int)
+	var index func(parentID int, categories []*pocketsmith.Category)
+	index = func(parentID int, categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			byTitle[key{parentID, category.Title}] = category.ID
+			index(category.ID, category.Children)
+		}
+	}
+	index(0, existing)
+
+	var restore func(parentID int, categories []*pocketsmith.Category) error
+	restore = func(parentID int, categories []*pocketsmith.Category) error {
+		for _, category := range categories {
+			id, ok := byTitle[key{parentID, category.Title}]
+			if !ok {
+				created, err := client.CreateCategory(userID, &pocketsmith.CreateCategory{
+					Title:           category.Title,
+					Colour:          category.Colour,
+					ParentID:        parentID,
+					IsTransfer:      category.IsTransfer,
+					IsBill:          category.IsBill,
+					RollUp:          category.RollUp,
+					RefundBehaviour: category.RefundBehaviour,
+				})
+				if err != nil {
+					return fmt.Errorf("error restoring category %q: %w", category.Title, err)
+				}
+				id = created.ID
+			}
+
+			ids.Categories[category.ID] = id
+			if err := restore(id, category.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return restore(0, categories)
+}
+
+func restoreCategoryRules(client *pocketsmith.Client, dir string, userID int, ids *IDMap) error {
+	var rules []*pocketsmith.CategoryRule
+	if err := readJSON(dir, categoryRulesFile, &rules); err != nil {
+		return err
+	}
+
+	existing, err := client.ListCategoryRules(userID)
+	if err != nil {
+		return fmt.Errorf("error listing category rules: %w", err)
+	}
+
+	type key struct {
+		categoryID   int
+		payeeMatches string
+	}
+	seen := make(map[key]bool)
+	for _, rule := range existing {
+		if rule.Category != nil {
+			seen[key{rule.Category.ID, rule.PayeeMatches}] = true
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Category == nil {
+			continue
+		}
+		categoryID, ok := ids.Categories[rule.Category.ID]
+		if !ok || seen[key{categoryID, rule.PayeeMatches}] {
+			continue
+		}
+
+		if _, err := client.CreateCategoryRule(categoryID, rule.PayeeMatches); err != nil {
+			return fmt.Errorf("error restoring category rule %q: %w", rule.PayeeMatches, err)
+		}
+		seen[key{categoryID, rule.PayeeMatches}] = true
+	}
+
+	return nil
+}
+
+func restoreTransactions(client *pocketsmith.Client, dir string, ids *IDMap) error {
+	f, err := os.Open(filepath.Join(dir, transactionsFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for decoder.More() {
+		var tx pocketsmith.DetailedTransaction
+		if err := decoder.Decode(&tx); err != nil {
+			return fmt.Errorf("error reading transactions: %w", err)
+		}
+
+		if tx.TransactionAccount == nil {
+			return fmt.Errorf("transaction %d has no transaction account", tx.ID)
+		}
+		transactionAccountID, ok := ids.TransactionAccounts[tx.TransactionAccount.ID]
+		if !ok {
+			return fmt.Errorf("transaction %d refers to unknown transaction account %d", tx.ID, tx.TransactionAccount.ID)
+		}
+
+		transaction := tx.Transaction()
+		if tx.Category != nil {
+			transaction.CategoryID = pocketsmith.CategoryID(ids.Categories[tx.Category.ID])
+		}
+
+		created, err := client.AddTransaction(transactionAccountID, transaction)
+		if err != nil {
+			return fmt.Errorf("error restoring transaction %d: %w", tx.ID, err)
+		}
+		ids.Transactions[tx.ID] = created.ID
+	}
+
+	return nil
+}
+
+func restoreAttachments(client *pocketsmith.Client, dir string, userID int, ids *IDMap) error {
+	var attachments []*pocketsmith.Attachment
+	if err := readJSON(dir, attachmentsFile, &attachments); err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		data, err := os.ReadFile(filepath.Join(dir, attachmentsDir, attachmentFileName(attachment)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		created, err := client.CreateAttachment(userID, &pocketsmith.CreateAttachment{
+			Title:    attachment.Title,
+			FileName: attachment.FileName,
+			FileData: base64.StdEncoding.EncodeToString(data),
+		})
+		if err != nil {
+			return fmt.Errorf("error restoring attachment %d: %w", attachment.ID, err)
+		}
+		ids.Attachments[attachment.ID] = created.ID
+
+		for _, transactionID := range attachedTransactions(attachment) {
+			newTransactionID, ok := ids.Transactions[transactionID]
+			if !ok {
+				continue
+			}
+			if err := client.AssignToTransaction(newTransactionID, created.ID); err != nil {
+				return fmt.Errorf("error assigning attachment %d: %w", attachment.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func attachedTransactions(attachment *pocketsmith.Attachment) []int64 {
+	var transactionIDs []int64
+	for _, attachable := range attachment.Attachables {
+		if attachable.Type == "Transaction" {
+			transactionIDs = append(transactionIDs, attachable.ID)
+		}
+	}
+	if len(transactionIDs) == 0 && attachment.AttachedTo != nil {
+		transactionIDs = append(transactionIDs, attachment.AttachedTo.ID)
+	}
+	return transactionIDs
+}
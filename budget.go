@@ -0,0 +1,72 @@
+package pocketsmith
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BudgetPeriod is the budget analysis of a category over a single period.
+type BudgetPeriod struct {
+	StartDate      string                     `json:"start_date"`
+	EndDate        string                     `json:"end_date"`
+	CurrencyCode   string                     `json:"currency_code"`
+	ActualAmount   float64                    `json:"actual_amount"`
+	ForecastAmount float64                    `json:"forecast_amount"`
+	RefundAmount   float64                    `json:"refund_amount"`
+	CurrentAmount  float64                    `json:"current_amount"`
+	OverBy         float64                    `json:"over_by"`
+	UnderBy        float64                    `json:"under_by"`
+	OverBudget     bool                       `json:"over_budget"`
+	UnderBudget    bool                       `json:"under_budget"`
+	PercentageUsed float64                    `json:"percentage_used"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// BudgetAnalysis summarises a category's income or expense budget across
+// periods.
+type BudgetAnalysis struct {
+	StartDate             string                     `json:"start_date"`
+	EndDate               string                     `json:"end_date"`
+	CurrencyCode          string                     `json:"currency_code"`
+	TotalActualAmount     float64                    `json:"total_actual_amount"`
+	AverageActualAmount   float64                    `json:"average_actual_amount"`
+	TotalForecastAmount   float64                    `json:"total_forecast_amount"`
+	AverageForecastAmount float64                    `json:"average_forecast_amount"`
+	TotalOverBy           float64                    `json:"total_over_by"`
+	TotalUnderBy          float64                    `json:"total_under_by"`
+	Periods               []*BudgetPeriod            `json:"periods"`
+	Extra                 map[string]json.RawMessage `json:"-"`
+}
+
+// BudgetAnalysisPackage is the budget for one category. Expense or Income is
+// nil when the category has no budget of that kind.
+type BudgetAnalysisPackage struct {
+	Category *Category                  `json:"category"`
+	Expense  *BudgetAnalysis            `json:"expense"`
+	Income   *BudgetAnalysis            `json:"income"`
+	Extra    map[string]json.RawMessage `json:"-"`
+}
+
+// ListBudget retrieves the user's budget, one package per budgeted category.
+// When rollUp is set, sub-category budgets are rolled up into their parents.
+func (c *Client) ListBudget(userID int, rollUp bool) ([]*BudgetAnalysisPackage, error) {
+	url := fmt.Sprintf("https://api.pocketsmith.com/v2/users/%d/budget", userID)
+	if rollUp {
+		url = fmt.Sprintf("%s?roll_up=true", url)
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("accept", "application/json")
+
+	var budget []*BudgetAnalysisPackage
+	if err := c.doAndDecode(req, &budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
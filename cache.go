@@ -0,0 +1,223 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response body stored in a CacheBackend, with the
+// validators the API returned for it.
+type CachedResponse struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+// CacheBackend stores cached responses. Keys start with the resource kind
+// they belong to, such as "institutions ", so that DeletePrefix can drop a
+// kind when it changes. Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	DeletePrefix(prefix string)
+}
+
+// MemoryCache is an in-memory CacheBackend.
+type MemoryCache struct {
+	mu        sync.Mutex
+	responses map[string]*CachedResponse
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{responses: make(map[string]*CachedResponse)}
+}
+
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	response, ok := m.responses[key]
+	return response, ok
+}
+
+func (m *MemoryCache) Set(key string, response *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[key] = response
+}
+
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.responses {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.responses, key)
+		}
+	}
+}
+
+// DefaultCacheTTLs caches reference data that rarely changes. Keys are
+// resource kinds: the last collection name in the request path, so both
+// GET /users/{id}/institutions and GET /institutions/{id} are
+// "institutions".
+var DefaultCacheTTLs = map[string]time.Duration{
+	"currencies":   24 * time.Hour,
+	"time_zones":   24 * time.Hour,
+	"categories":   10 * time.Minute,
+	"institutions": 10 * time.Minute,
+}
+
+// relatedKinds lists the kinds whose cached responses also change when a
+// kind is modified, for example account balances when a transaction is
+// added.
+var relatedKinds = map[string][]string{
+	"transactions":         {"transaction_accounts", "accounts", "budget"},
+	"transaction_accounts": {"accounts"},
+	"accounts":             {"transaction_accounts"},
+	"institutions":         {"accounts", "transaction_accounts"},
+	"categories":           {"category_rules", "budget"},
+	"category_rules":       {"categories"},
+	"events":               {"budget"},
+}
+
+type responseCache struct {
+	backend CacheBackend
+	ttls    map[string]time.Duration
+	// prefix scopes keys to the client's token, so clients for different
+	// users can share a backend.
+	prefix string
+}
+
+// WithCache caches GET responses for the resource kinds in ttls, or
+// DefaultCacheTTLs when ttls is nil. Expired responses are revalidated with
+// If-None-Match or If-Modified-Since when the API sent an ETag or
+// Last-Modified header. Successful POST, PUT and DELETE requests made through
+// the client drop the cached responses of the kind they modify and of related
+// kinds.
+func WithCache(backend CacheBackend, ttls map[string]time.Duration) ClientOption {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	return func(c *Client) {
+		sum := sha256.Sum256([]byte(c.token))
+		c.cache = &responseCache{backend: backend, ttls: ttls, prefix: hex.EncodeToString(sum[:8]) + " "}
+	}
+}
+
+// InvalidateCache drops every cached response of the given resource kinds,
+// or of all kinds when none are given.
+func (c *Client) InvalidateCache(kinds ...string) {
+	if c.cache == nil {
+		return
+	}
+	if len(kinds) == 0 {
+		for kind := range c.cache.ttls {
+			kinds = append(kinds, kind)
+		}
+	}
+	for _, kind := range kinds {
+		c.cache.backend.DeletePrefix(kind + " " + c.cache.prefix)
+	}
+}
+
+// resourceKind returns the last collection name in an API path: the
+// segments after /v2/ alternate between collection names and IDs.
+func resourceKind(path string) string {
+	path = strings.TrimPrefix(path, "/v2")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	return segments[(len(segments)-1)/2*2]
+}
+
+// send performs the request, answering GET requests from the cache when it
+// can, and returns the response body.
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	if c.cache == nil {
+		return c.sendUncached(req)
+	}
+
+	kind := resourceKind(req.URL.Path)
+	if req.Method != http.MethodGet {
+		body, status, err := c.roundTrip(req)
+		if err == nil && status < 400 {
+			c.InvalidateCache(append([]string{kind}, relatedKinds[kind]...)...)
+		}
+		return body, err
+	}
+
+	ttl, ok := c.cache.ttls[kind]
+	if !ok {
+		return c.sendUncached(req)
+	}
+
+	key := kind + " " + c.cache.prefix + req.URL.String()
+	cached, ok := c.cache.backend.Get(key)
+	if ok && time.Now().Before(cached.Expires) {
+		return cached.Body, nil
+	}
+	if ok {
+		// The conditional headers only apply to this attempt, so they go on
+		// a copy rather than the caller's request.
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		refreshed := *cached
+		refreshed.Expires = time.Now().Add(ttl)
+		c.cache.backend.Set(key, &refreshed)
+		return cached.Body, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		c.cache.backend.Set(key, &CachedResponse{
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Expires:      time.Now().Add(ttl),
+		})
+	}
+
+	return body, nil
+}
+
+func (c *Client) sendUncached(req *http.Request) ([]byte, error) {
+	body, _, err := c.roundTrip(req)
+	return body, err
+}
+
+func (c *Client) roundTrip(req *http.Request) ([]byte, int, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	bodyBuf := new(bytes.Buffer)
+	if _, err := bodyBuf.ReadFrom(resp.Body); err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return bodyBuf.Bytes(), resp.StatusCode, nil
+}
@@ -1,6 +1,7 @@
 package pocketsmith
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,26 +20,28 @@ func (c CategoryID) MarshalJSON() ([]byte, error) {
 }
 
 type Category struct {
-	ID              int         `json:"id"`
-	Title           string      `json:"title"`
-	Colour          string      `json:"colour"`
-	IsTransfer      bool        `json:"is_transfer"`
-	IsBill          bool        `json:"is_bill"`
-	RefundBehaviour string      `json:"refund_behaviour"`
-	RolloverType    string      `json:"rollover_type"`
-	Children        []*Category `json:"children"`
-	ParentID        int         `json:"parent_id"`
-	RollUp          bool        `json:"roll_up"`
-	CreatedAt       string      `json:"created_at"`
-	UpdatedAt       string      `json:"updated_at"`
+	ID              int                        `json:"id"`
+	Title           string                     `json:"title"`
+	Colour          string                     `json:"colour"`
+	IsTransfer      bool                       `json:"is_transfer"`
+	IsBill          bool                       `json:"is_bill"`
+	RefundBehaviour string                     `json:"refund_behaviour"`
+	RolloverType    string                     `json:"rollover_type"`
+	Children        []*Category                `json:"children"`
+	ParentID        int                        `json:"parent_id"`
+	RollUp          bool                       `json:"roll_up"`
+	CreatedAt       string                     `json:"created_at"`
+	UpdatedAt       string                     `json:"updated_at"`
+	Extra           map[string]json.RawMessage `json:"-"`
 }
 
 type CategoryRule struct {
-	ID           int64     `json:"id"`
-	Category     *Category `json:"category"`
-	PayeeMatches string    `json:"payee_matches"`
-	CreatedAt    string    `json:"created_at"`
-	UpdatedAt    string    `json:"updated_at"`
+	ID           int64                      `json:"id"`
+	Category     *Category                  `json:"category"`
+	PayeeMatches string                     `json:"payee_matches"`
+	CreatedAt    string                     `json:"created_at"`
+	UpdatedAt    string                     `json:"updated_at"`
+	Extra        map[string]json.RawMessage `json:"-"`
 }
 
 func (rule *CategoryRule) Matches(target string) bool {
@@ -100,3 +103,70 @@ func (c *Client) GetCategory(categoryID int) (*Category, error) {
 
 	return &category, nil
 }
+
+// CreateCategory holds the fields accepted by POST /users/{id}/categories.
+// ParentID may be left zero to create a top-level category.
+type CreateCategory struct {
+	Title           string `json:"title"`
+	Colour          string `json:"colour,omitempty"`
+	ParentID        int    `json:"parent_id,omitempty"`
+	IsTransfer      bool   `json:"is_transfer"`
+	IsBill          bool   `json:"is_bill"`
+	RollUp          bool   `json:"roll_up"`
+	RefundBehaviour string `json:"refund_behaviour,omitempty"`
+}
+
+// CreateCategory creates a new category for a user.
+func (c *Client) CreateCategory(userID int, category *CreateCategory) (*Category, error) {
+	url := fmt.Sprintf("https://api.pocketsmith.com/v2/users/%d/categories", userID)
+
+	payload, err := json.Marshal(category)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("accept", "application/json")
+	req.Header.Add("content-type", "application/json")
+
+	var created Category
+	if err := c.doAndDecode(req, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// CreateCategoryRule creates a rule that assigns transactions whose payee
+// contains payeeMatches to a category.
+func (c *Client) CreateCategoryRule(categoryID int, payeeMatches string) (*CategoryRule, error) {
+	url := fmt.Sprintf("https://api.pocketsmith.com/v2/categories/%d/category_rules", categoryID)
+
+	payload, err := json.Marshal(struct {
+		PayeeMatches string `json:"payee_matches"`
+	}{
+		PayeeMatches: payeeMatches,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("accept", "application/json")
+	req.Header.Add("content-type", "application/json")
+
+	var rule CategoryRule
+	if err := c.doAndDecode(req, &rule); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
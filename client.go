@@ -19,11 +19,33 @@ func (a ApiError) Error() string {
 }
 
 type Client struct {
-	token string
+	token         string
+	httpClient    *http.Client
+	cache         *responseCache
+	beforeRequest []func(*http.Request)
+	afterResponse []func(*http.Request, *HookResponse)
+	tracer        Tracer
+	reportSchema  func(SchemaIssue)
+	extraFields   bool
+	dryRun        *Plan
 }
 
-func NewClient(token string) *Client {
-	return &Client{token: token}
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used for API requests, for example to
+// set a timeout or wrap its transport.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(token string, opts ...ClientOption) *Client {
+	c := &Client{token: token, httpClient: &http.Client{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Client) doAndDecode(req *http.Request, responseType any) error {
@@ -31,22 +53,16 @@ func (c *Client) doAndDecode(req *http.Request, responseType any) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Add("X-Developer-Key", c.token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if c.dryRun != nil && req.Method != http.MethodGet {
+		return c.plan(req, responseType)
+	}
+
+	body, err := c.send(req)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	bodyBuf := new(bytes.Buffer)
-	bodyBuf.ReadFrom(resp.Body)
-
-	reader := bytes.NewReader(bodyBuf.Bytes())
-
-	// b := bytes.Buffer{}
-	// b.ReadFrom(reader)
-	// fmt.Println("Body: ", string(b.Bytes()))
-	// reader.Seek(0, 0)
+	reader := bytes.NewReader(body)
 
 	var apiError ApiError
 	if err := json.NewDecoder(reader).Decode(&apiError); err == nil {
@@ -57,7 +73,20 @@ func (c *Client) doAndDecode(req *http.Request, responseType any) error {
 
 	reader.Seek(0, 0)
 	if responseType != nil {
-		return json.NewDecoder(reader).Decode(responseType)
+		err := json.NewDecoder(reader).Decode(responseType)
+		if c.reportSchema == nil && !c.extraFields {
+			return err
+		}
+
+		var typeErr *json.UnmarshalTypeError
+		if err != nil && !errors.As(err, &typeErr) {
+			return err
+		}
+		c.checkSchema(req, body, responseType)
+		if c.reportSchema != nil {
+			return nil
+		}
+		return err
 	}
 
 	return nil
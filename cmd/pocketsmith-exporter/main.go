@@ -0,0 +1,44 @@
+// Command pocketsmith-exporter serves the current user's account balances and
+// API request metrics on /metrics for Prometheus to scrape.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/metrics"
+)
+
+func main() {
+	addr := flag.String("listen", "localhost:9734", "address to serve /metrics on")
+	interval := flag.Duration("interval", 5*time.Minute, "how often to refresh balances")
+	flag.Parse()
+
+	token := os.Getenv("POCKETSMITH_TOKEN")
+	if token == "" {
+		log.Fatal("POCKETSMITH_TOKEN environment variable is required")
+	}
+
+	exporter := metrics.NewExporter(nil, 0, metrics.WithRefreshInterval(*interval))
+	client := pocketsmith.NewClient(token, pocketsmith.WithHTTPClient(&http.Client{
+		Transport: exporter.Transport(nil),
+		Timeout:   time.Minute,
+	}))
+
+	currentUser, err := client.GetCurrentUser()
+	if currentUser == nil || err != nil {
+		log.Fatal("Failed to get current user")
+	}
+
+	exporter.SetClient(client, currentUser.ID)
+	go exporter.Run(context.Background())
+
+	http.Handle("/metrics", exporter)
+	log.Printf("serving metrics on http://%s/metrics", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,108 @@
+// Command pocketsmith-mcp runs a Model Context Protocol server exposing the
+// current PocketSmith user's data to LLM assistants.
+//
+// By default it speaks over stdio. With -http it listens for HTTP requests on
+// the given address at /mcp instead. An address without a host listens on
+// localhost only. HTTP clients must send the token from -http-token or the
+// POCKETSMITH_MCP_TOKEN environment variable as a bearer token.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/mcp"
+)
+
+func main() {
+	addr := flag.String("http", "", "listen on this address instead of using stdio, e.g. localhost:8080")
+	readOnly := flag.Bool("read-only", false, "disable tools that change data")
+	httpToken := flag.String("http-token", os.Getenv("POCKETSMITH_MCP_TOKEN"), "bearer token HTTP clients must send (default $POCKETSMITH_MCP_TOKEN)")
+	allowHosts := flag.String("allow-hosts", "", "comma-separated hosts HTTP requests may name besides localhost")
+	allowOrigins := flag.String("allow-origins", "", "comma-separated browser origins allowed besides localhost")
+	flag.Parse()
+
+	if *addr != "" && *httpToken == "" {
+		log.Fatal("-http requires -http-token or the POCKETSMITH_MCP_TOKEN environment variable")
+	}
+
+	token := os.Getenv("POCKETSMITH_TOKEN")
+	if token == "" {
+		log.Fatal("POCKETSMITH_TOKEN environment variable is required")
+	}
+
+	client := pocketsmith.NewClient(token)
+
+	currentUser, err := client.GetCurrentUser()
+	if currentUser == nil || err != nil {
+		log.Fatal("Failed to get current user")
+	}
+
+	var opts []mcp.ServerOption
+	if *readOnly {
+		opts = append(opts, mcp.WithReadOnly())
+	}
+	if *httpToken != "" {
+		opts = append(opts, mcp.WithBearerToken(*httpToken))
+	}
+	if *allowHosts != "" {
+		opts = append(opts, mcp.WithAllowedHosts(splitList(*allowHosts)...))
+	}
+	if *allowOrigins != "" {
+		opts = append(opts, mcp.WithAllowedOrigins(splitList(*allowOrigins)...))
+	}
+	server := mcp.NewServer(client, currentUser.ID, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *addr != "" {
+		listen := *addr
+		if host, port, err := net.SplitHostPort(listen); err == nil && host == "" {
+			listen = net.JoinHostPort("localhost", port)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", server)
+		httpServer := &http.Server{Addr: listen, Handler: mux}
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				log.Printf("error shutting down: %v", err)
+			}
+		}()
+
+		log.Printf("listening on http://%s/mcp", listen)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Logs go to stderr, since stdout carries the protocol.
+	if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,55 @@
+// Command pocketsmith-review reviews transactions from the terminal.
+//
+// It pages through transactions that need review (or, with -uncategorised,
+// transactions without a category), suggests a category for each from the
+// user's category rules and from how the same payee was categorised before,
+// and saves the decision with UpdateTransaction. Changes can be undone during
+// the session.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+func main() {
+	uncategorised := flag.Bool("uncategorised", false, "review uncategorised transactions instead of those needing review")
+	start := flag.String("start", "", "only transactions on or after this date, YYYY-MM-DD")
+	flag.Parse()
+
+	token := os.Getenv("POCKETSMITH_TOKEN")
+	if token == "" {
+		log.Fatal("POCKETSMITH_TOKEN environment variable is required")
+	}
+
+	client := pocketsmith.NewClient(token)
+
+	currentUser, err := client.GetCurrentUser()
+	if currentUser == nil || err != nil {
+		log.Fatal("Failed to get current user")
+	}
+
+	opts := []pocketsmith.ListTransactionsOption{pocketsmith.WithNeedsReview(1)}
+	if *uncategorised {
+		opts = []pocketsmith.ListTransactionsOption{pocketsmith.WithUncategorised(1)}
+	}
+	if *start != "" {
+		opts = append(opts, pocketsmith.WithStartDate(*start))
+	}
+
+	session, err := newSession(client, currentUser.ID, bufio.NewReader(os.Stdin), os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := session.run(opts); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Reviewed %d transactions.\n", session.reviewed)
+}
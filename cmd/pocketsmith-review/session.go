@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type session struct {
+	client *pocketsmith.Client
+	userID int
+	in     *bufio.Reader
+	out    io.Writer
+
+	categories []*pocketsmith.Category
+	byID       map[int]*pocketsmith.Category
+	rules      []*pocketsmith.CategoryRule
+	history    map[string]map[int]int
+
+	handled  map[int64]bool
+	undo     []change
+	reviewed int
+	// undone holds restored transactions waiting to be reviewed again.
+	undone []*pocketsmith.DetailedTransaction
+}
+
+// change records a transaction's state before it was updated, so it can be
+// restored.
+type change struct {
+	id     int64
+	before *pocketsmith.Transaction
+}
+
+// suggestion is a proposed category and why it was proposed.
+type suggestion struct {
+	category *pocketsmith.Category
+	reason   string
+}
+
+func newSession(client *pocketsmith.Client, userID int, in *bufio.Reader, out io.Writer) (*session, error) {
+	categories, err := client.ListCategories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	rules, err := client.ListCategoryRules(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing category rules: %w", err)
+	}
+	// Prefer the most specific rule when several match.
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].PayeeMatches) > len(rules[j].PayeeMatches)
+	})
+
+	s := &session{
+		client:  client,
+		userID:  userID,
+		in:      in,
+		out:     out,
+		byID:    make(map[int]*pocketsmith.Category),
+		rules:   rules,
+		history: make(map[string]map[int]int),
+		handled: make(map[int64]bool),
+	}
+
+	var walk func(categories []*pocketsmith.Category)
+	walk = func(categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			s.categories = append(s.categories, category)
+			s.byID[category.ID] = category
+			walk(category.Children)
+		}
+	}
+	walk(categories)
+
+	return s, nil
+}
+
+// run reviews transactions page by page. Reviewed transactions drop out of
+// the filtered list, so a page is fetched again until everything on it has
+// been handled.
+func (s *session) run(opts []pocketsmith.ListTransactionsOption) error {
+	page := 1
+	for {
+		transactions, err := s.client.ListTransactionsInUser(s.userID, append(opts, pocketsmith.WithPage(page))...)
+		if err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+
+		pending := 0
+		for _, tx := range transactions {
+			if s.handled[tx.ID] {
+				continue
+			}
+			pending++
+
+			quit, err := s.review(tx)
+			if err != nil {
+				return err
+			}
+			if quit {
+				return nil
+			}
+
+			if quit, err := s.reviewUndone(); err != nil || quit {
+				return err
+			}
+		}
+
+		if pending == 0 {
+			page++
+		}
+	}
+}
+
+// reviewUndone reviews the transactions restored by undo, most recent first.
+// It returns true when the user quits.
+func (s *session) reviewUndone() (bool, error) {
+	for len(s.undone) > 0 {
+		tx := s.undone[len(s.undone)-1]
+		s.undone = s.undone[:len(s.undone)-1]
+
+		quit, err := s.review(tx)
+		if err != nil || quit {
+			return quit, err
+		}
+	}
+	return false, nil
+}
+
+// review prompts for a single transaction until it is saved or skipped. It
+// returns true when the user quits.
+func (s *session) review(tx *pocketsmith.DetailedTransaction) (bool, error) {
+	edit := tx.Transaction()
+	suggested := s.suggest(tx)
+	var chosen *pocketsmith.Category
+
+	s.show(tx, suggested)
+
+	for {
+		fmt.Fprint(s.out, "[enter] save  c <category>  l <labels>  n <note>  s skip  u undo  q quit > ")
+		line, err := s.in.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return true, nil
+		}
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+
+		command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch command {
+		case "":
+			switch {
+			case chosen != nil:
+				edit.CategoryID = pocketsmith.CategoryID(chosen.ID)
+			case suggested != nil:
+				edit.CategoryID = pocketsmith.CategoryID(suggested.category.ID)
+			}
+			edit.NeedsReview = false
+
+			if err := s.save(tx, edit); err != nil {
+				fmt.Fprintln(s.out, "error:", err)
+				continue
+			}
+			return false, nil
+		case "c":
+			category, err := s.findCategory(arg)
+			if err != nil {
+				fmt.Fprintln(s.out, err)
+				continue
+			}
+			chosen = category
+			fmt.Fprintf(s.out, "  category: %s\n", category.Title)
+		case "l":
+			for _, label := range strings.Split(arg, ",") {
+				if label = strings.TrimSpace(label); label != "" && !contains(edit.Labels, label) {
+					edit.Labels = append(edit.Labels, label)
+				}
+			}
+			fmt.Fprintf(s.out, "  labels: %s\n", strings.Join(edit.Labels, ", "))
+		case "n":
+			edit.Note = arg
+			fmt.Fprintf(s.out, "  note: %s\n", edit.Note)
+		case "s":
+			s.handled[tx.ID] = true
+			return false, nil
+		case "u":
+			if err := s.undoLast(); err != nil {
+				fmt.Fprintln(s.out, "error:", err)
+			}
+			s.show(tx, suggested)
+		case "q":
+			return true, nil
+		default:
+			fmt.Fprintf(s.out, "unknown command %q\n", command)
+		}
+	}
+}
+
+func (s *session) show(tx *pocketsmith.DetailedTransaction, suggested *suggestion) {
+	account := ""
+	if tx.TransactionAccount != nil {
+		account = tx.TransactionAccount.Name
+	}
+
+	fmt.Fprintf(s.out, "\n%s  %10.2f  %s  [%s]\n", tx.Date, tx.Amount, tx.Payee, account)
+	if tx.OriginalPayee != "" && tx.OriginalPayee != tx.Payee {
+		fmt.Fprintf(s.out, "  original payee: %s\n", tx.OriginalPayee)
+	}
+	if tx.Memo != "" {
+		fmt.Fprintf(s.out, "  memo: %s\n", tx.Memo)
+	}
+	if tx.Category != nil {
+		fmt.Fprintf(s.out, "  category: %s\n", tx.Category.Title)
+	}
+	if len(tx.Labels) > 0 {
+		fmt.Fprintf(s.out, "  labels: %s\n", strings.Join(tx.Labels, ", "))
+	}
+	if suggested != nil {
+		fmt.Fprintf(s.out, "  suggestion: %s (%s)\n", suggested.category.Title, suggested.reason)
+	}
+}
+
+func (s *session) save(tx *pocketsmith.DetailedTransaction, edit *pocketsmith.Transaction) error {
+	before := tx.Transaction()
+	if _, err := s.client.UpdateTransaction(tx.ID, edit); err != nil {
+		return err
+	}
+
+	s.handled[tx.ID] = true
+	s.undo = append(s.undo, change{id: tx.ID, before: before})
+	s.reviewed++
+
+	return nil
+}
+
+// undoLast restores the most recently saved transaction and queues it to be
+// reviewed again once the current transaction is done.
+func (s *session) undoLast() error {
+	if len(s.undo) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+
+	// UpdateTransaction leaves out empty fields, which would keep what the
+	// review added, so the earlier state is sent in full.
+	last := s.undo[len(s.undo)-1]
+	restored, err := s.client.ReplaceTransaction(last.id, last.before)
+	if err != nil {
+		return err
+	}
+
+	s.undo = s.undo[:len(s.undo)-1]
+	delete(s.handled, last.id)
+	s.undone = append(s.undone, restored)
+	s.reviewed--
+	fmt.Fprintf(s.out, "  restored transaction %d; it comes up again after this one\n", last.id)
+
+	return nil
+}
+
+// suggest proposes a category from the first matching category rule, or
+// otherwise from the category most often used for the same payee.
+func (s *session) suggest(tx *pocketsmith.DetailedTransaction) *suggestion {
+	for _, rule := range s.rules {
+		if rule.Category == nil {
+			continue
+		}
+		if rule.Matches(tx.Payee) || rule.Matches(tx.OriginalPayee) {
+			category := s.byID[rule.Category.ID]
+			if category == nil {
+				category = rule.Category
+			}
+			return &suggestion{category: category, reason: fmt.Sprintf("rule %q", rule.PayeeMatches)}
+		}
+	}
+
+	counts := s.payeeHistory(tx.Payee)
+	bestID, best, total := 0, 0, 0
+	for id, count := range counts {
+		total += count
+		if count > best || (count == best && id < bestID) {
+			bestID, best = id, count
+		}
+	}
+	if category, ok := s.byID[bestID]; ok {
+		return &suggestion{category: category, reason: fmt.Sprintf("used %d of %d times for this payee", best, total)}
+	}
+
+	return nil
+}
+
+// payeeHistory counts how often each category was used for the payee.
+func (s *session) payeeHistory(payee string) map[int]int {
+	if counts, ok := s.history[payee]; ok {
+		return counts
+	}
+
+	counts := make(map[int]int)
+	s.history[payee] = counts
+
+	if payee == "" {
+		return counts
+	}
+
+	transactions, err := s.client.ListTransactionsInUser(s.userID, pocketsmith.WithSearch(payee))
+	if err != nil {
+		return counts
+	}
+	for _, tx := range transactions {
+		if tx.Category != nil && tx.Payee == payee && !tx.NeedsReview {
+			counts[tx.Category.ID]++
+		}
+	}
+
+	return counts
+}
+
+// findCategory finds a category by ID, by title, or by a unique
+// case-insensitive part of its title.
+func (s *session) findCategory(query string) (*pocketsmith.Category, error) {
+	if query == "" {
+		return nil, fmt.Errorf("usage: c <category id or title>")
+	}
+
+	if id, err := strconv.Atoi(query); err == nil {
+		if category, ok := s.byID[id]; ok {
+			return category, nil
+		}
+		return nil, fmt.Errorf("no category with ID %d", id)
+	}
+
+	var matches []*pocketsmith.Category
+	for _, category := range s.categories {
+		if strings.EqualFold(category.Title, query) {
+			return category, nil
+		}
+		if strings.Contains(strings.ToLower(category.Title), strings.ToLower(query)) {
+			matches = append(matches, category)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no category matches %q", query)
+	case 1:
+		return matches[0], nil
+	}
+
+	titles := make([]string, 0, len(matches))
+	for _, category := range matches {
+		titles = append(titles, fmt.Sprintf("%s (%d)", category.Title, category.ID))
+	}
+	return nil, fmt.Errorf("%q matches several categories: %s", query, strings.Join(titles, ", "))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
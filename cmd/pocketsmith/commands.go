@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+var (
+	accountColumns            = []string{"id", "title", "type", "currency_code", "current_balance", "current_balance_in_base_currency"}
+	transactionAccountColumns = []string{"id", "account_id", "name", "type", "currency_code", "institution.title", "current_balance"}
+	institutionColumns        = []string{"id", "title", "currency_code"}
+	categoryColumns           = []string{"id", "title", "parent_id", "is_transfer", "is_bill", "roll_up"}
+	ruleColumns               = []string{"id", "payee_matches", "category.id", "category.title"}
+	transactionColumns        = []string{"id", "date", "payee", "amount", "category.title", "transaction_account.name", "labels", "needs_review"}
+	attachmentColumns         = []string{"id", "title", "file_name", "content_type", "assigned", "created_at"}
+	currencyColumns           = []string{"id", "name", "symbol", "minor_unit"}
+)
+
+func runMe(a *app, args []string) error {
+	user, err := a.client.GetCurrentUser()
+	if err != nil {
+		return err
+	}
+	return a.print(user, []string{"id", "login", "name", "email", "base_currency_code", "time_zone"})
+}
+
+func runAccounts(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	accounts, err := a.client.ListAccounts(userID)
+	if err != nil {
+		return err
+	}
+	return a.print(accounts, accountColumns)
+}
+
+func runTransactionAccounts(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	transactionAccounts, err := a.client.ListTransactionAccounts(userID)
+	if err != nil {
+		return err
+	}
+	return a.print(transactionAccounts, transactionAccountColumns)
+}
+
+func runInstitutions(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	institutions, err := a.client.ListInstitutions(userID)
+	if err != nil {
+		return err
+	}
+	return a.print(institutions, institutionColumns)
+}
+
+func runCategories(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	categories, err := a.client.ListCategories(userID)
+	if err != nil {
+		return err
+	}
+
+	if a.output == "json" {
+		return a.print(categories, categoryColumns)
+	}
+
+	var flat []*pocketsmith.Category
+	var walk func(parentID int, categories []*pocketsmith.Category)
+	walk = func(parentID int, categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			c := *category
+			c.Children = nil
+			if c.ParentID == 0 {
+				c.ParentID = parentID
+			}
+			flat = append(flat, &c)
+			walk(category.ID, category.Children)
+		}
+	}
+	walk(0, categories)
+
+	return a.print(flat, categoryColumns)
+}
+
+func runRules(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	rules, err := a.client.ListCategoryRules(userID)
+	if err != nil {
+		return err
+	}
+	return a.print(rules, ruleColumns)
+}
+
+func runLabels(a *app, args []string) error {
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+	labels, err := a.client.ListLabels(userID)
+	if err != nil {
+		return err
+	}
+
+	if a.output == "json" {
+		return a.print(labels, nil)
+	}
+
+	type row struct {
+		Label string `json:"label"`
+	}
+	rows := make([]row, 0, len(labels))
+	for _, label := range labels {
+		rows = append(rows, row{Label: string(label)})
+	}
+	return a.print(rows, []string{"label"})
+}
+
+func runCurrencies(a *app, args []string) error {
+	currencies, err := a.client.ListCurrencies()
+	if err != nil {
+		return err
+	}
+	return a.print(currencies, currencyColumns)
+}
+
+func runTransactions(a *app, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: transactions list|get|add|update|delete")
+	}
+
+	switch args[0] {
+	case "list":
+		return listTransactions(a, args[1:])
+	case "get":
+		id, err := idArg(args[1:], "transactions get <id>")
+		if err != nil {
+			return err
+		}
+		tx, err := a.client.GetTransaction(id)
+		if err != nil {
+			return err
+		}
+		return a.print(tx, transactionColumns)
+	case "add":
+		return addTransaction(a, args[1:])
+	case "update":
+		return updateTransaction(a, args[1:])
+	case "delete":
+		id, err := idArg(args[1:], "transactions delete <id>")
+		if err != nil {
+			return err
+		}
+		return a.client.DeleteTransaction(id)
+	}
+
+	return fmt.Errorf("unknown transactions command %q", args[0])
+}
+
+func listTransactions(a *app, args []string) error {
+	flags := flag.NewFlagSet("transactions list", flag.ContinueOnError)
+	account := flags.Int("account", 0, "transaction account ID (defaults to all of the user's accounts)")
+	start := flags.String("start", "", "start date, YYYY-MM-DD")
+	end := flags.String("end", "", "end date, YYYY-MM-DD")
+	search := flags.String("search", "", "search query")
+	needsReview := flags.Bool("needs-review", false, "only transactions needing review")
+	uncategorised := flags.Bool("uncategorised", false, "only uncategorised transactions")
+	page := flags.Int("page", 0, "page number")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var opts []pocketsmith.ListTransactionsOption
+	if *start != "" {
+		opts = append(opts, pocketsmith.WithStartDate(*start))
+	}
+	if *end != "" {
+		opts = append(opts, pocketsmith.WithEndDate(*end))
+	}
+	if *search != "" {
+		opts = append(opts, pocketsmith.WithSearch(*search))
+	}
+	if *needsReview {
+		opts = append(opts, pocketsmith.WithNeedsReview(1))
+	}
+	if *uncategorised {
+		opts = append(opts, pocketsmith.WithUncategorised(1))
+	}
+	if *page > 0 {
+		opts = append(opts, pocketsmith.WithPage(*page))
+	}
+
+	var (
+		transactions []*pocketsmith.DetailedTransaction
+		err          error
+	)
+	if *account != 0 {
+		transactions, err = a.client.ListTransactionsInTransactionAccount(*account, opts...)
+	} else {
+		var userID int
+		if userID, err = a.user(); err != nil {
+			return err
+		}
+		transactions, err = a.client.ListTransactionsInUser(userID, opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.print(transactions, transactionColumns)
+}
+
+// transactionFlags registers the writable transaction fields on flags.
+type transactionFlags struct {
+	payee, date, note, memo, labels, cheque *string
+	amount                                  *float64
+	category                                *int
+	transfer, needsReview                   *bool
+}
+
+func newTransactionFlags(flags *flag.FlagSet) *transactionFlags {
+	return &transactionFlags{
+		payee:       flags.String("payee", "", "payee"),
+		amount:      flags.Float64("amount", 0, "amount, negative for debits"),
+		date:        flags.String("date", "", "date, YYYY-MM-DD"),
+		category:    flags.Int("category", 0, "category ID, -1 to remove the category"),
+		note:        flags.String("note", "", "note"),
+		memo:        flags.String("memo", "", "memo"),
+		labels:      flags.String("labels", "", "comma-separated labels"),
+		cheque:      flags.String("cheque", "", "cheque number"),
+		transfer:    flags.Bool("transfer", false, "mark as a transfer"),
+		needsReview: flags.Bool("needs-review", false, "mark as needing review"),
+	}
+}
+
+// apply copies the flags that were set on the command line into tx.
+func (f *transactionFlags) apply(flags *flag.FlagSet, tx *pocketsmith.Transaction) {
+	flags.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "payee":
+			tx.Payee = *f.payee
+		case "amount":
+			tx.Amount = *f.amount
+		case "date":
+			tx.Date = *f.date
+		case "category":
+			tx.CategoryID = pocketsmith.CategoryID(*f.category)
+		case "note":
+			tx.Note = *f.note
+		case "memo":
+			tx.Memo = *f.memo
+		case "labels":
+			tx.Labels = splitLabels(*f.labels)
+		case "cheque":
+			tx.ChequeNumber = *f.cheque
+		case "transfer":
+			tx.IsTransfer = *f.transfer
+		case "needs-review":
+			tx.NeedsReview = *f.needsReview
+		}
+	})
+}
+
+func addTransaction(a *app, args []string) error {
+	flags := flag.NewFlagSet("transactions add", flag.ContinueOnError)
+	account := flags.Int("account", 0, "transaction account ID (required)")
+	fields := newTransactionFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *account == 0 || *fields.payee == "" || *fields.date == "" {
+		return errors.New("-account, -payee and -date are required")
+	}
+
+	tx := &pocketsmith.Transaction{}
+	fields.apply(flags, tx)
+
+	created, err := a.client.AddTransaction(*account, tx)
+	if err != nil {
+		return err
+	}
+	return a.print(created, []string{"id", "date", "payee", "amount"})
+}
+
+// updateTransaction fetches the transaction first, since the API replaces
+// every field sent and the flags only cover the fields being changed.
+func updateTransaction(a *app, args []string) error {
+	flags := flag.NewFlagSet("transactions update", flag.ContinueOnError)
+	fields := newTransactionFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := idArg(flags.Args(), "transactions update [flags] <id>")
+	if err != nil {
+		return err
+	}
+
+	existing, err := a.client.GetTransaction(id)
+	if err != nil {
+		return err
+	}
+
+	tx := existing.Transaction()
+	fields.apply(flags, tx)
+
+	updated, err := a.client.UpdateTransaction(id, tx)
+	if err != nil {
+		return err
+	}
+	return a.print(updated, transactionColumns)
+}
+
+func runAttachments(a *app, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: attachments list|upload|download|assign")
+	}
+
+	switch args[0] {
+	case "list":
+		flags := flag.NewFlagSet("attachments list", flag.ContinueOnError)
+		unassigned := flags.Bool("unassigned", false, "only unassigned attachments")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		userID, err := a.user()
+		if err != nil {
+			return err
+		}
+		attachments, err := a.client.ListAttachments(userID, *unassigned)
+		if err != nil {
+			return err
+		}
+		return a.print(attachments, attachmentColumns)
+	case "upload":
+		return uploadAttachment(a, args[1:])
+	case "download":
+		return downloadAttachment(a, args[1:])
+	case "assign":
+		if len(args) != 3 {
+			return errors.New("usage: attachments assign <attachment id> <transaction id>")
+		}
+		attachmentID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid attachment ID %q", args[1])
+		}
+		transactionID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID %q", args[2])
+		}
+		return a.client.AssignToTransaction(transactionID, attachmentID)
+	}
+
+	return fmt.Errorf("unknown attachments command %q", args[0])
+}
+
+func uploadAttachment(a *app, args []string) error {
+	flags := flag.NewFlagSet("attachments upload", flag.ContinueOnError)
+	title := flags.String("title", "", "title (defaults to the file name)")
+	transaction := flags.Int64("transaction", 0, "transaction ID to assign the attachment to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: attachments upload [-title title] [-transaction id] <file>")
+	}
+
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if *title == "" {
+		*title = filepath.Base(path)
+	}
+
+	userID, err := a.user()
+	if err != nil {
+		return err
+	}
+
+	attachment, err := a.client.CreateAttachment(userID, &pocketsmith.CreateAttachment{
+		Title:    *title,
+		FileName: filepath.Base(path),
+		FileData: base64.StdEncoding.EncodeToString(data),
+	})
+	if err != nil {
+		return err
+	}
+
+	if *transaction != 0 {
+		if err := a.client.AssignToTransaction(*transaction, attachment.ID); err != nil {
+			return err
+		}
+	}
+
+	return a.print(attachment, attachmentColumns)
+}
+
+func downloadAttachment(a *app, args []string) error {
+	flags := flag.NewFlagSet("attachments download", flag.ContinueOnError)
+	out := flags.String("out", "", "output path (defaults to the attachment's file name)")
+	timeout := flags.Duration("timeout", 5*time.Minute, "maximum time to spend downloading")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := idArg(flags.Args(), "attachments download [-out path] <id>")
+	if err != nil {
+		return err
+	}
+
+	attachment, err := a.client.GetAttachment(id)
+	if err != nil {
+		return err
+	}
+	if attachment.OriginalURL == "" {
+		return fmt.Errorf("attachment %d has no file", id)
+	}
+
+	path := *out
+	if path == "" {
+		path = filepath.Base(attachment.FileName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := a.client.DownloadAttachment(ctx, attachment, f); err != nil {
+		return fmt.Errorf("error downloading attachment: %w", err)
+	}
+
+	return f.Close()
+}
+
+func idArg(args []string, usage string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("usage: %s", usage)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", args[0])
+	}
+	return id, nil
+}
+
+func splitLabels(labels string) []string {
+	var split []string
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			split = append(split, label)
+		}
+	}
+	return split
+}
@@ -0,0 +1,168 @@
+// Command pocketsmith is a command-line client for the PocketSmith API.
+//
+// The developer key is read from the POCKETSMITH_TOKEN environment variable,
+// or from the "token" field of ~/.config/pocketsmith/config.json.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type command struct {
+	usage string
+	run   func(app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"me":                   {"show the current user", runMe},
+	"accounts":             {"list accounts", runAccounts},
+	"transaction-accounts": {"list transaction accounts", runTransactionAccounts},
+	"institutions":         {"list institutions", runInstitutions},
+	"categories":           {"list categories", runCategories},
+	"rules":                {"list category rules", runRules},
+	"labels":               {"list labels", runLabels},
+	"transactions":         {"list|get|add|update|delete transactions", runTransactions},
+	"attachments":          {"list|upload|download|assign attachments", runAttachments},
+	"currencies":           {"list currencies", runCurrencies},
+}
+
+type app struct {
+	client *pocketsmith.Client
+	output string
+	userID int
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "pocketsmith:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("pocketsmith", flag.ContinueOnError)
+	output := flags.String("o", "table", "output format: table, json or csv")
+	userID := flags.Int("user", 0, "user ID (defaults to the current user)")
+	dryRun := flags.Bool("dry-run", false, "print the changes a command would make instead of making them")
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		return errors.New("missing command")
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		usage(flags)
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	switch *output {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	token, err := loadToken()
+	if err != nil {
+		return err
+	}
+
+	var opts []pocketsmith.ClientOption
+	plan := &pocketsmith.Plan{}
+	if *dryRun {
+		opts = append(opts, pocketsmith.WithDryRun(plan))
+	}
+
+	a := &app{client: pocketsmith.NewClient(token, opts...), output: *output, userID: *userID}
+	if err := cmd.run(a, flags.Args()[1:]); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintln(os.Stderr, "dry run, not sent:")
+		return plan.Write(os.Stderr)
+	}
+	return nil
+}
+
+func usage(flags *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "usage: pocketsmith [-o table|json|csv] [-user id] [-dry-run] <command> [args]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flags.PrintDefaults()
+}
+
+// loadToken returns POCKETSMITH_TOKEN, falling back to the config file.
+func loadToken() (string, error) {
+	if token := os.Getenv("POCKETSMITH_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.New("POCKETSMITH_TOKEN is not set")
+	}
+
+	path := filepath.Join(dir, "pocketsmith", "config.json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("POCKETSMITH_TOKEN is not set and %s does not exist", path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var config struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if strings.TrimSpace(config.Token) == "" {
+		return "", fmt.Errorf("no token in %s", path)
+	}
+
+	return strings.TrimSpace(config.Token), nil
+}
+
+// user returns the -user flag, or the current user's ID.
+func (a *app) user() (int, error) {
+	if a.userID != 0 {
+		return a.userID, nil
+	}
+
+	user, err := a.client.GetCurrentUser()
+	if err != nil {
+		return 0, err
+	}
+	a.userID = user.ID
+
+	return a.userID, nil
+}
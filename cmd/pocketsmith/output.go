@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dvcrn/pocketsmith-go/export"
+)
+
+// print writes records in the selected output format. records may be a single
+// record or a slice. columns select the fields shown in table and CSV output.
+func (a *app) print(records any, columns []string) error {
+	if a.output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+
+	var list []any
+	v := reflect.ValueOf(records)
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, v.Index(i).Interface())
+		}
+	} else {
+		list = []any{records}
+	}
+
+	if a.output == "csv" {
+		w := export.NewCSVWriter(os.Stdout, columns)
+		for _, record := range list {
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, record := range list {
+		fields, err := export.Flatten(record)
+		if err != nil {
+			return err
+		}
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = fields[column]
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
@@ -1,33 +1,37 @@
 package pocketsmith
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
 // CurrencySeparators are the formatting characters used for a currency.
 type CurrencySeparators struct {
-	Major string `json:"major"`
-	Minor string `json:"minor"`
+	Major string                     `json:"major"`
+	Minor string                     `json:"minor"`
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Currency is a currency supported by PocketSmith.
 type Currency struct {
-	ID         string             `json:"id"`
-	Name       string             `json:"name"`
-	Symbol     string             `json:"symbol"`
-	MinorUnit  int                `json:"minor_unit"`
-	Separators CurrencySeparators `json:"separators"`
+	ID         string                     `json:"id"`
+	Name       string                     `json:"name"`
+	Symbol     string                     `json:"symbol"`
+	MinorUnit  int                        `json:"minor_unit"`
+	Separators CurrencySeparators         `json:"separators"`
+	Extra      map[string]json.RawMessage `json:"-"`
 }
 
 // TimeZone is a time zone supported by PocketSmith.
 type TimeZone struct {
-	Name            string `json:"name"`
-	UTCOffset       int    `json:"utc_offset"`
-	FormattedName   string `json:"formatted_name"`
-	FormattedOffset string `json:"formatted_offset"`
-	Abbreviation    string `json:"abbreviation"`
-	Identifier      string `json:"identifier"`
+	Name            string                     `json:"name"`
+	UTCOffset       int                        `json:"utc_offset"`
+	FormattedName   string                     `json:"formatted_name"`
+	FormattedOffset string                     `json:"formatted_offset"`
+	Abbreviation    string                     `json:"abbreviation"`
+	Identifier      string                     `json:"identifier"`
+	Extra           map[string]json.RawMessage `json:"-"`
 }
 
 // ListCurrencies retrieves all currencies supported by PocketSmith.
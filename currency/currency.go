@@ -0,0 +1,219 @@
+// Package currency converts amounts between currencies using exchange rates
+// derived from PocketSmith's own data, optionally backed by another rate
+// provider, and formats amounts the way PocketSmith does.
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// ErrNoRate is returned when no exchange rate is known for a currency.
+var ErrNoRate = errors.New("no exchange rate")
+
+// RateProvider supplies exchange rates, for example from a central bank or
+// a commercial service. Rate returns how many units of to one unit of from
+// buys on date, YYYY-MM-DD; date may be empty for the latest rate. It returns
+// an error wrapping ErrNoRate when it has no rate.
+type RateProvider interface {
+	Rate(from, to, date string) (float64, error)
+}
+
+// RateProviderFunc adapts a function to RateProvider.
+type RateProviderFunc func(from, to, date string) (float64, error)
+
+func (f RateProviderFunc) Rate(from, to, date string) (float64, error) {
+	return f(from, to, date)
+}
+
+// Converter converts between currencies through the user's base currency.
+// Rates to the base currency are learnt from accounts, transaction accounts
+// and transactions, which PocketSmith returns with amounts in both
+// currencies. It is safe for concurrent use.
+type Converter struct {
+	base     string
+	provider RateProvider
+
+	mu     sync.RWMutex
+	latest map[string]rate
+	daily  map[string]map[string]float64
+}
+
+type rate struct {
+	date  string
+	value float64
+}
+
+// NewConverter returns a Converter for the user's base currency. provider
+// may be nil; when set, it is asked for rates the converter hasn't learnt
+// for the date requested.
+func NewConverter(baseCurrency string, provider RateProvider) *Converter {
+	return &Converter{
+		base:     strings.ToLower(baseCurrency),
+		provider: provider,
+		latest:   make(map[string]rate),
+		daily:    make(map[string]map[string]float64),
+	}
+}
+
+// SetRate records that one unit of currency was worth value units of the
+// base currency on date. date may be empty for a rate with no date.
+func (c *Converter) SetRate(currency, date string, value float64) {
+	if value <= 0 {
+		return
+	}
+	currency = strings.ToLower(currency)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if date != "" {
+		if c.daily[currency] == nil {
+			c.daily[currency] = make(map[string]float64)
+		}
+		c.daily[currency][date] = value
+	}
+	if latest, ok := c.latest[currency]; !ok || date >= latest.date {
+		c.latest[currency] = rate{date: date, value: value}
+	}
+}
+
+// AddAccounts learns rates from the accounts' current balances.
+func (c *Converter) AddAccounts(accounts []*pocketsmith.Account) {
+	for _, account := range accounts {
+		c.addBalance(account.CurrencyCode, account.CurrentBalanceDate, account.CurrentBalanceExchangeRate,
+			account.CurrentBalance, account.CurrentBalanceInBaseCurrency)
+	}
+}
+
+// AddTransactionAccounts learns rates from the transaction accounts' current
+// balances.
+func (c *Converter) AddTransactionAccounts(accounts []*pocketsmith.TransactionAccount) {
+	for _, account := range accounts {
+		c.addBalance(account.CurrencyCode, account.CurrentBalanceDate, account.CurrentBalanceExchangeRate,
+			account.CurrentBalance, account.CurrentBalanceInBaseCurrency)
+	}
+}
+
+func (c *Converter) addBalance(currency, date string, exchangeRate, balance, inBase float64) {
+	if currency == "" {
+		return
+	}
+	if exchangeRate > 0 {
+		c.SetRate(currency, date, exchangeRate)
+	} else if balance != 0 && inBase != 0 {
+		c.SetRate(currency, date, inBase/balance)
+	}
+}
+
+// AddTransactions learns daily rates from transactions' amounts in their
+// account's currency and the base currency. Transactions without a
+// transaction account are skipped, as their currency is unknown.
+func (c *Converter) AddTransactions(transactions []*pocketsmith.DetailedTransaction) {
+	for _, tx := range transactions {
+		if tx.TransactionAccount == nil || tx.TransactionAccount.CurrencyCode == "" {
+			continue
+		}
+		if tx.Amount == 0 || tx.AmountInBaseCurrency == 0 {
+			continue
+		}
+		c.SetRate(tx.TransactionAccount.CurrencyCode, tx.Date, tx.AmountInBaseCurrency/tx.Amount)
+	}
+}
+
+// Rate returns how many units of to one unit of from buys on date,
+// YYYY-MM-DD, or at the latest known rate when date is empty. A rate learnt
+// for that exact date is preferred, then the provider's rate, then the
+// latest learnt rate.
+func (c *Converter) Rate(from, to, date string) (float64, error) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if from == to {
+		return 1, nil
+	}
+
+	fromRate, fromExact := c.toBase(from, date)
+	toRate, toExact := c.toBase(to, date)
+	if fromExact && toExact {
+		return fromRate / toRate, nil
+	}
+
+	if c.provider != nil {
+		value, err := c.provider.Rate(from, to, date)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrNoRate) {
+			return 0, fmt.Errorf("error getting rate from %s to %s: %w", from, to, err)
+		}
+	}
+
+	if fromRate == 0 || toRate == 0 {
+		return 0, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+	}
+	return fromRate / toRate, nil
+}
+
+// toBase returns the currency's rate to the base currency and whether it was
+// learnt for date itself.
+func (c *Converter) toBase(currency, date string) (float64, bool) {
+	if currency == c.base {
+		return 1, true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if date != "" {
+		if value, ok := c.daily[currency][date]; ok {
+			return value, true
+		}
+	}
+	latest, ok := c.latest[currency]
+	if !ok {
+		return 0, false
+	}
+	return latest.value, date == "" || latest.date == date
+}
+
+// Convert converts amount from one currency to another at the rate on date.
+// It can be used as a transfers.ConvertFunc.
+func (c *Converter) Convert(amount float64, from, to, date string) (float64, error) {
+	value, err := c.Rate(from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * value, nil
+}
+
+// ToBase converts amount to the base currency.
+func (c *Converter) ToBase(amount float64, from, date string) (float64, error) {
+	return c.Convert(amount, from, c.base, date)
+}
+
+// Load returns a Converter for the user's base currency with rates learnt
+// from their accounts and transaction accounts.
+func Load(client *pocketsmith.Client, userID int, provider RateProvider) (*Converter, error) {
+	user, err := client.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	accounts, err := client.ListAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %w", err)
+	}
+
+	transactionAccounts, err := client.ListTransactionAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transaction accounts: %w", err)
+	}
+
+	c := NewConverter(user.BaseCurrencyCode, provider)
+	c.AddAccounts(accounts)
+	c.AddTransactionAccounts(transactionAccounts)
+	return c, nil
+}
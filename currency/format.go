@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Format formats amount with the currency's symbol, number of decimal places
+// and separators, for example "-$1,234.50".
+func Format(amount float64, currency *pocketsmith.Currency) string {
+	digits := currency.MinorUnit
+	if digits < 0 {
+		digits = 0
+	}
+	major, minor := currency.Separators.Major, currency.Separators.Minor
+	if minor == "" {
+		minor = "."
+	}
+
+	text := fmt.Sprintf("%.*f", digits, math.Abs(amount))
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	if amount < 0 && strings.Trim(text, "0.") != "" {
+		b.WriteString("-")
+	}
+	b.WriteString(currency.Symbol)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(major)
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString(minor)
+		b.WriteString(fraction)
+	}
+
+	return b.String()
+}
+
+// Currencies caches the currency list from ListCurrencies. It is safe for
+// concurrent use.
+type Currencies struct {
+	client *pocketsmith.Client
+	// TTL is how long the list is kept before being fetched again. Zero
+	// keeps it for the life of the cache.
+	TTL time.Duration
+
+	mu         sync.Mutex
+	currencies map[string]*pocketsmith.Currency
+	fetched    time.Time
+}
+
+func NewCurrencies(client *pocketsmith.Client) *Currencies {
+	return &Currencies{client: client}
+}
+
+// Get returns the currency with the given code, such as "nzd" or "NZD".
+func (c *Currencies) Get(code string) (*pocketsmith.Currency, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.currencies == nil || (c.TTL > 0 && time.Since(c.fetched) > c.TTL) {
+		list, err := c.client.ListCurrencies()
+		if err != nil {
+			return nil, fmt.Errorf("error listing currencies: %w", err)
+		}
+		c.currencies = make(map[string]*pocketsmith.Currency, len(list))
+		for _, currency := range list {
+			c.currencies[strings.ToLower(currency.ID)] = currency
+		}
+		c.fetched = time.Now()
+	}
+
+	currency, ok := c.currencies[strings.ToLower(code)]
+	if !ok {
+		return nil, fmt.Errorf("unknown currency %q", code)
+	}
+	return currency, nil
+}
+
+// Format formats amount in the currency with the given code.
+func (c *Currencies) Format(amount float64, code string) (string, error) {
+	currency, err := c.Get(code)
+	if err != nil {
+		return "", err
+	}
+	return Format(amount, currency), nil
+}
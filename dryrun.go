@@ -0,0 +1,248 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlannedRequest is a mutating request a dry-run client didn't send.
+type PlannedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Plan collects the requests made by a dry-run client. It is safe for
+// concurrent use.
+type Plan struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+	nextID   int
+}
+
+// Requests returns the planned requests in the order they were made.
+func (p *Plan) Requests() []PlannedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedRequest(nil), p.requests...)
+}
+
+// Write writes one line per planned request: method, URL and JSON body.
+func (p *Plan) Write(w io.Writer) error {
+	for _, req := range p.Requests() {
+		line := req.Method + " " + req.URL
+		if len(req.Body) > 0 {
+			line += " " + string(req.Body)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add records a request. For a POST it returns the ID the created resource
+// gets: negative, so it can't be mistaken for a real one. IDs count down from
+// -1.
+func (p *Plan) add(req PlannedRequest) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+	if req.Method != http.MethodPost {
+		return 0
+	}
+	p.nextID--
+	return p.nextID
+}
+
+// issued reports whether id was given to a resource created in the plan, so
+// later requests may refer to it.
+func (p *Plan) issued(id int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return id < 0 && id >= p.nextID
+}
+
+// WithDryRun stops the client from sending POST, PUT and DELETE requests.
+// Each is validated and added to plan instead, and the call returns a
+// synthetic result built from the request: created resources get a negative
+// ID, which later planned requests may refer to, and updated ones keep the ID
+// in the URL. GET requests are still sent,
+// so scripts can read current data while planning changes.
+func WithDryRun(plan *Plan) ClientOption {
+	return func(c *Client) {
+		c.dryRun = plan
+	}
+}
+
+// requiredFields lists, per resource kind, the fields a POST must set.
+var requiredFields = map[string][]string{
+	"transactions": {"payee", "date"},
+	"accounts":     {"title", "currency_code", "type"},
+	"institutions": {"title", "currency_code"},
+	"categories":   {"title"},
+	"events":       {"category_id", "date", "repeat_type"},
+}
+
+// dateFields are request fields that must be YYYY-MM-DD dates when set.
+var dateFields = []string{"date", "starting_balance_date"}
+
+// plan validates a mutating request, records it and decodes a synthetic
+// result into responseType.
+func (c *Client) plan(req *http.Request, responseType any) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.dryRun.validate(req, body); err != nil {
+		return fmt.Errorf("dry run: %s %s: %w", req.Method, req.URL, err)
+	}
+
+	planned := PlannedRequest{Method: req.Method, URL: req.URL.String()}
+	if len(body) > 0 {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, body); err == nil {
+			planned.Body = compact.Bytes()
+		}
+	}
+	createdID := c.dryRun.add(planned)
+
+	if responseType == nil {
+		return nil
+	}
+
+	id := strconv.Itoa(createdID)
+	if req.Method != http.MethodPost {
+		id = pathID(req.URL.Path)
+	}
+	return decodeSynthetic(body, id, responseType)
+}
+
+// validate checks a mutating request before it is planned. IDs in the path
+// must be positive, or ones the plan gave to resources it created.
+func (p *Plan) validate(req *http.Request, body []byte) error {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 2; i < len(segments); i += 2 {
+		if id, err := strconv.Atoi(segments[i]); err == nil && id <= 0 && !p.issued(id) {
+			return fmt.Errorf("invalid ID %d for %s", id, segments[i-1])
+		}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		// Array bodies have no fields to check.
+		if jsonKind(body) == "array" {
+			return nil
+		}
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	if req.Method == http.MethodPost {
+		for _, name := range requiredFields[resourceKind(req.URL.Path)] {
+			if value, ok := fields[name]; !ok || value == nil || value == "" {
+				return fmt.Errorf("%s is required", name)
+			}
+		}
+	}
+
+	for _, name := range dateFields {
+		if value, ok := fields[name].(string); ok && value != "" {
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return fmt.Errorf("%s %q is not a YYYY-MM-DD date", name, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathID returns the last ID in the path, the ID of the resource being
+// changed. Segments after /v2/ alternate between collection names and IDs.
+func pathID(path string) string {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/v2"), "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[(len(segments)-2)/2*2+1]
+}
+
+// decodeSynthetic fills responseType from the request body, which mostly
+// uses the same field names as responses, and sets its ID, as a number or a
+// string to suit the response type. For list responses the body's array, or
+// the only array in a body object, is used. Fields that don't fit the
+// response type are left zero.
+func decodeSynthetic(body []byte, id string, responseType any) error {
+	value := reflect.ValueOf(responseType).Elem()
+
+	if value.Kind() == reflect.Slice {
+		if jsonKind(body) == "object" {
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(body, &fields); err == nil {
+				for _, field := range fields {
+					if jsonKind(field) == "array" {
+						body = field
+						break
+					}
+				}
+			}
+		}
+		if jsonKind(body) == "array" {
+			json.Unmarshal(body, responseType)
+		}
+		return nil
+	}
+
+	fields := make(map[string]any)
+	if jsonKind(body) == "object" {
+		json.Unmarshal(body, &fields)
+	}
+	if stringID(value.Type()) {
+		fields["id"] = id
+	} else if n, err := strconv.Atoi(id); err == nil {
+		fields["id"] = n
+	}
+
+	synthetic, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	json.Unmarshal(synthetic, responseType)
+	return nil
+}
+
+// stringID reports whether t, after following pointers, is a struct whose
+// "id" field is a string, like Event's.
+func stringID(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "id" {
+			return field.Type.Kind() == reflect.String
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// EventRepeatType is how often a budget event repeats.
+type EventRepeatType string
+
+const (
+	EventRepeatOnce        EventRepeatType = "once"
+	EventRepeatDaily       EventRepeatType = "daily"
+	EventRepeatWeekly      EventRepeatType = "weekly"
+	EventRepeatFortnightly EventRepeatType = "fortnightly"
+	EventRepeatMonthly     EventRepeatType = "monthly"
+	EventRepeatYearly      EventRepeatType = "yearly"
+	EventRepeatEachWeekday EventRepeatType = "each weekday"
+)
+
+// Event is a budget event in a scenario, such as an expected bill.
+type Event struct {
+	ID                   string                     `json:"id"`
+	Category             *Category                  `json:"category"`
+	Scenario             *Scenario                  `json:"scenario"`
+	Amount               float64                    `json:"amount"`
+	AmountInBaseCurrency float64                    `json:"amount_in_base_currency"`
+	CurrencyCode         string                     `json:"currency_code"`
+	Date                 string                     `json:"date"`
+	Colour               string                     `json:"colour"`
+	Note                 string                     `json:"note"`
+	RepeatType           EventRepeatType            `json:"repeat_type"`
+	RepeatInterval       int                        `json:"repeat_interval"`
+	SeriesID             int                        `json:"series_id"`
+	SeriesStartID        string                     `json:"series_start_id"`
+	InfiniteSeries       bool                       `json:"infinite_series"`
+	Extra                map[string]json.RawMessage `json:"-"`
+}
+
+// CreateEvent holds the fields accepted by POST /scenarios/{id}/events.
+// RepeatInterval multiplies RepeatType, so a quarterly event is monthly with
+// an interval of 3.
+type CreateEvent struct {
+	CategoryID     int             `json:"category_id"`
+	Amount         float64         `json:"amount"`
+	Date           string          `json:"date"`
+	RepeatType     EventRepeatType `json:"repeat_type"`
+	RepeatInterval int             `json:"repeat_interval"`
+	Note           string          `json:"note,omitempty"`
+}
+
+// CreateEvent creates a budget event in a scenario. An account's scenarios
+// are listed on Account.Scenarios.
+func (c *Client) CreateEvent(scenarioID int, event *CreateEvent) (*Event, error) {
+	url := fmt.Sprintf("https://api.pocketsmith.com/v2/scenarios/%d/events", scenarioID)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("accept", "application/json")
+	req.Header.Add("content-type", "application/json")
+
+	var created Event
+	if err := c.doAndDecode(req, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CSVWriter writes records as CSV rows, flattening nested objects into
+// dotted column names. Lists of values, such as labels, are joined with ";"
+// and lists of objects are written as JSON.
+type CSVWriter struct {
+	w             *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+// NewCSVWriter returns a CSVWriter with the given columns, for example
+// TransactionColumns. If columns is empty, every field of the first record is
+// used, in alphabetical order.
+func NewCSVWriter(w io.Writer, columns []string) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (c *CSVWriter) Write(record any) error {
+	fields, err := Flatten(record)
+	if err != nil {
+		return err
+	}
+
+	if !c.headerWritten {
+		if len(c.columns) == 0 {
+			for name := range fields {
+				c.columns = append(c.columns, name)
+			}
+			sort.Strings(c.columns)
+		}
+		if err := c.w.Write(c.columns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		row[i] = fields[column]
+	}
+
+	return c.w.Write(row)
+}
+
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Flatten converts a record into a map of dotted field names to string
+// values, using the record's JSON field names.
+func Flatten(record any) (map[string]string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	flatten(fields, "", value)
+	return fields, nil
+}
+
+func flatten(fields map[string]string, name string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if name != "" {
+				key = name + "." + key
+			}
+			flatten(fields, key, child)
+		}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				data, _ := json.Marshal(v)
+				fields[name] = string(data)
+				return
+			}
+			values = append(values, scalar(item))
+		}
+		fields[name] = strings.Join(values, ";")
+	default:
+		fields[name] = scalar(v)
+	}
+}
+
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
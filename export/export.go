@@ -0,0 +1,130 @@
+// Package export streams PocketSmith records to CSV and JSON Lines.
+//
+// Transactions are fetched a page at a time and written as they arrive, so
+// long histories are never held in memory at once.
+package export
+
+import (
+	"fmt"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Writer writes records of any of the library's model types.
+type Writer interface {
+	Write(record any) error
+	Flush() error
+}
+
+// Default CSV columns for each record type. Nested fields are addressed with
+// dots, for example "category.title".
+var (
+	TransactionColumns = []string{
+		"id", "date", "payee", "original_payee", "amount", "amount_in_base_currency",
+		"category.id", "category.title", "transaction_account.id", "transaction_account.name",
+		"transaction_account.currency_code", "memo", "note", "labels", "cheque_number",
+		"type", "status", "is_transfer", "needs_review", "closing_balance",
+		"created_at", "updated_at",
+	}
+	AccountColumns = []string{
+		"id", "title", "type", "currency_code", "is_net_worth", "include_in_net_worth",
+		"current_balance", "current_balance_date", "current_balance_in_base_currency",
+		"current_balance_exchange_rate", "safe_balance", "primary_transaction_account.id",
+		"primary_transaction_account.institution.title", "created_at", "updated_at",
+	}
+	TransactionAccountColumns = []string{
+		"id", "account_id", "name", "number", "type", "currency_code", "institution.id",
+		"institution.title", "current_balance", "current_balance_date",
+		"current_balance_in_base_currency", "starting_balance", "starting_balance_date",
+		"include_in_net_worth", "offline", "created_at", "updated_at",
+	}
+	CategoryColumns = []string{
+		"id", "title", "parent_id", "colour", "is_transfer", "is_bill", "roll_up",
+		"refund_behaviour", "rollover_type", "created_at", "updated_at",
+	}
+)
+
+// Transactions writes all of the user's transactions, one page at a time.
+// opts are passed to ListTransactionsInUser.
+func Transactions(client *pocketsmith.Client, userID int, w Writer, opts ...pocketsmith.ListTransactionsOption) error {
+	err := client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		for _, tx := range page {
+			if err := w.Write(tx); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// Accounts writes all of the user's accounts.
+func Accounts(client *pocketsmith.Client, userID int, w Writer) error {
+	accounts, err := client.ListAccounts(userID)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		if err := w.Write(account); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// TransactionAccounts writes all of the user's transaction accounts.
+func TransactionAccounts(client *pocketsmith.Client, userID int, w Writer) error {
+	transactionAccounts, err := client.ListTransactionAccounts(userID)
+	if err != nil {
+		return fmt.Errorf("error listing transaction accounts: %w", err)
+	}
+
+	for _, transactionAccount := range transactionAccounts {
+		if err := w.Write(transactionAccount); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// Categories writes all of the user's categories. The category tree is
+// flattened: every category is written as its own record, without children,
+// and sub-categories refer to their parent through parent_id.
+func Categories(client *pocketsmith.Client, userID int, w Writer) error {
+	categories, err := client.ListCategories(userID)
+	if err != nil {
+		return fmt.Errorf("error listing categories: %w", err)
+	}
+
+	var walk func(parentID int, categories []*pocketsmith.Category) error
+	walk = func(parentID int, categories []*pocketsmith.Category) error {
+		for _, category := range categories {
+			flat := *category
+			flat.Children = nil
+			if flat.ParentID == 0 {
+				flat.ParentID = parentID
+			}
+
+			if err := w.Write(&flat); err != nil {
+				return err
+			}
+			if err := walk(category.ID, category.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(0, categories); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// JSONLinesWriter writes each record as a JSON object on its own line, keeping
+// nested objects intact.
+type JSONLinesWriter struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+}
+
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	bw := bufio.NewWriter(w)
+	return &JSONLinesWriter{w: bw, encoder: json.NewEncoder(bw)}
+}
+
+func (j *JSONLinesWriter) Write(record any) error {
+	return j.encoder.Encode(record)
+}
+
+func (j *JSONLinesWriter) Flush() error {
+	return j.w.Flush()
+}
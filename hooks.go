@@ -0,0 +1,221 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// HookResponse describes the outcome of an API request for
+// WithAfterResponse hooks. Body is the full response body; Err is set when
+// the request failed before a response was received.
+type HookResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+	Err        error
+}
+
+// WithBeforeRequest adds a hook called with each API request before it is
+// sent. Hooks may add headers but must not consume the body.
+func WithBeforeRequest(hook func(req *http.Request)) ClientOption {
+	return func(c *Client) {
+		c.beforeRequest = append(c.beforeRequest, hook)
+	}
+}
+
+// WithAfterResponse adds a hook called after each API request completes,
+// whether it succeeded or not. Requests answered from the response cache
+// aren't sent and don't call hooks.
+func WithAfterResponse(hook func(req *http.Request, resp *HookResponse)) ClientOption {
+	return func(c *Client) {
+		c.afterResponse = append(c.afterResponse, hook)
+	}
+}
+
+// Span is a trace span for one API request.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans. Its shape follows OpenTelemetry's trace.Tracer, so an
+// OpenTelemetry tracer can be adapted with a small wrapper; the context it
+// returns is attached to the request, so an instrumented transport can
+// propagate the span.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// WithTracer starts a span for each API request, named after its method and
+// route, such as "GET /v2/users/{id}/accounts", with OpenTelemetry's HTTP
+// client attributes.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// DefaultMaxLogBody is how many bytes of a body WithLogger logs.
+const DefaultMaxLogBody = 4096
+
+// WithLogger logs each API request and response to logger: method, URL,
+// status and duration, at debug level for successes and at warn level for
+// failures. With logBodies set, headers and the first DefaultMaxLogBody bytes
+// of request and response bodies are logged too. The developer key is
+// always redacted.
+func WithLogger(logger *slog.Logger, logBodies bool) ClientOption {
+	return func(c *Client) {
+		c.beforeRequest = append(c.beforeRequest, func(req *http.Request) {
+			if !logBodies {
+				return
+			}
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.String()),
+				slog.Any("header", redactHeader(req.Header)),
+			}
+			if body := requestBody(req); len(body) > 0 {
+				attrs = append(attrs, slog.String("body", truncate(body)))
+			}
+			logger.Debug("pocketsmith request", attrs...)
+		})
+
+		c.afterResponse = append(c.afterResponse, func(req *http.Request, resp *HookResponse) {
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.String()),
+				slog.Duration("duration", resp.Duration),
+			}
+			if resp.Err != nil {
+				logger.Warn("pocketsmith request failed", append(attrs, slog.Any("error", resp.Err))...)
+				return
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			if logBodies {
+				attrs = append(attrs, slog.Any("header", resp.Header), slog.String("body", truncate(resp.Body)))
+			}
+			if resp.StatusCode >= 400 {
+				logger.Warn("pocketsmith response", attrs...)
+			} else {
+				logger.Debug("pocketsmith response", attrs...)
+			}
+		})
+	}
+}
+
+// redactHeader returns a copy of the header with the developer key replaced.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted.Get("X-Developer-Key") != "" {
+		redacted.Set("X-Developer-Key", "REDACTED")
+	}
+	return redacted
+}
+
+// requestBody returns a copy of the request body, which http.NewRequest
+// makes available through GetBody for in-memory bodies.
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+func truncate(body []byte) string {
+	if len(body) > DefaultMaxLogBody {
+		return string(body[:DefaultMaxLogBody]) + "..."
+	}
+	return string(body)
+}
+
+// APIHost is the host the client sends API requests to.
+const APIHost = "api.pocketsmith.com"
+
+// idSegment matches numeric path segments, which Route replaces with "{id}".
+var idSegment = regexp.MustCompile(`/[0-9][0-9,]*(/|$)`)
+
+// Route returns the path of an API request with IDs replaced by "{id}", such
+// as "/v2/users/{id}/transactions", so it can name spans and label metrics
+// without growing with every record requested. Requests to other hosts, such
+// as attachment downloads, all return "other".
+func Route(req *http.Request) string {
+	if req.URL.Hostname() != APIHost {
+		return "other"
+	}
+	route := req.URL.Path
+	// Replace twice, as adjacent matches share a slash.
+	route = idSegment.ReplaceAllString(route, "/{id}$1")
+	route = idSegment.ReplaceAllString(route, "/{id}$1")
+	return route
+}
+
+func spanName(req *http.Request) string {
+	return req.Method + " " + Route(req)
+}
+
+// do sends an API request, running the hooks and tracer around it. When
+// hooks or a tracer are set, the response body is read in full and replaced
+// so they can see it.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	var span Span
+	if c.tracer != nil {
+		var ctx context.Context
+		ctx, span = c.tracer.Start(req.Context(), spanName(req))
+		req = req.WithContext(ctx)
+		span.SetAttribute("http.request.method", req.Method)
+		span.SetAttribute("url.full", req.URL.String())
+		span.SetAttribute("server.address", req.URL.Hostname())
+		defer span.End()
+	}
+
+	for _, hook := range c.beforeRequest {
+		hook(req)
+	}
+
+	if span == nil && len(c.afterResponse) == 0 {
+		return c.httpClient.Do(req)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	info := &HookResponse{Err: err}
+	if err == nil {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		info.StatusCode, info.Header, info.Body, info.Err = resp.StatusCode, resp.Header, body, readErr
+	}
+	info.Duration = time.Since(start)
+
+	for _, hook := range c.afterResponse {
+		hook(req, info)
+	}
+
+	if span != nil {
+		if info.Err != nil {
+			span.RecordError(info.Err)
+		} else {
+			span.SetAttribute("http.response.status_code", info.StatusCode)
+			if info.StatusCode >= 400 {
+				span.RecordError(fmt.Errorf("HTTP %d", info.StatusCode))
+			}
+		}
+	}
+
+	return resp, err
+}
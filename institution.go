@@ -9,14 +9,15 @@ import (
 )
 
 type Institution struct {
-	ID             int    `json:"id"`
-	Title          string `json:"title"`
-	CurrencyCode   string `json:"currency_code"`
-	Colour         string `json:"colour"`
-	LogoURL        string `json:"logo_url"`
-	FaviconDataURI string `json:"favicon_data_uri"`
-	CreatedAt      string `json:"created_at"`
-	UpdatedAt      string `json:"updated_at"`
+	ID             int                        `json:"id"`
+	Title          string                     `json:"title"`
+	CurrencyCode   string                     `json:"currency_code"`
+	Colour         string                     `json:"colour"`
+	LogoURL        string                     `json:"logo_url"`
+	FaviconDataURI string                     `json:"favicon_data_uri"`
+	CreatedAt      string                     `json:"created_at"`
+	UpdatedAt      string                     `json:"updated_at"`
+	Extra          map[string]json.RawMessage `json:"-"`
 }
 
 // GetInstitution retrieves a single institution by its ID.
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// writeBeancount writes the journal in Beancount syntax. Account names are
+// rewritten to satisfy Beancount's stricter rules, and an open directive is
+// written for every account on the date it is first used.
+func (j *Journal) writeBeancount(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if j.BaseCurrency != "" {
+		fmt.Fprintf(bw, "option \"operating_currency\" %s\n\n", strconv.Quote(j.BaseCurrency))
+	}
+
+	accounts := make([]string, 0, len(j.Opened))
+	for account := range j.Opened {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(a, b int) bool {
+		if j.Opened[accounts[a]] != j.Opened[accounts[b]] {
+			return j.Opened[accounts[a]] < j.Opened[accounts[b]]
+		}
+		return accounts[a] < accounts[b]
+	})
+	for _, account := range accounts {
+		fmt.Fprintf(bw, "%s open %s\n", j.Opened[account], beancountAccount(account))
+	}
+
+	for _, entry := range j.Entries {
+		bw.WriteString("\n")
+
+		flag := "*"
+		if entry.Pending {
+			flag = "!"
+		}
+		fmt.Fprintf(bw, "%s %s %s %s", entry.Date, flag, strconv.Quote(singleLine(entry.Payee)), strconv.Quote(singleLine(entry.Memo)))
+		for _, tag := range entry.Tags {
+			if tag = beancountTag(tag); tag != "" {
+				fmt.Fprintf(bw, " #%s", tag)
+			}
+		}
+		bw.WriteString("\n")
+
+		if entry.Note != "" {
+			fmt.Fprintf(bw, "  note: %s\n", strconv.Quote(singleLine(entry.Note)))
+		}
+
+		for _, p := range entry.Postings {
+			fmt.Fprintf(bw, "  %-50s  %s\n", beancountAccount(p.Account), j.amount(p))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// beancountAccount rewrites each component of an account name so it starts
+// with a capital letter or digit and contains only letters, digits and
+// dashes.
+func beancountAccount(account string) string {
+	parts := strings.Split(account, ":")
+	for i, part := range parts {
+		var b strings.Builder
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+		}) {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			b.WriteString(string(runes))
+		}
+
+		component := strings.TrimLeft(b.String(), "-")
+		if component == "" || !(unicode.IsUpper([]rune(component)[0]) || unicode.IsDigit([]rune(component)[0])) {
+			component = "X" + component
+		}
+		parts[i] = component
+	}
+	return strings.Join(parts, ":")
+}
+
+func beancountTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_', r == '/', r == '.':
+			return r
+		case unicode.IsSpace(r):
+			return '-'
+		}
+		return -1
+	}, tag)
+}
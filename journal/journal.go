@@ -0,0 +1,251 @@
+// Package journal exports PocketSmith transactions as plain-text accounting
+// journals for Ledger, hledger and Beancount.
+package journal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type Format string
+
+const (
+	FormatLedger    Format = "ledger"
+	FormatHledger   Format = "hledger"
+	FormatBeancount Format = "beancount"
+)
+
+// transferWindow is how far apart the two legs of a transfer may be dated and
+// still be written as a single entry.
+const transferWindow = 5 * 24 * time.Hour
+
+// Posting is one leg of an entry. Price is set when the posting is in a
+// currency other than the base currency and holds the total value in the
+// base currency.
+type Posting struct {
+	Account  string
+	Amount   float64
+	Currency string
+	Price    float64
+}
+
+// Entry is a balanced journal entry.
+type Entry struct {
+	Date     string
+	Payee    string
+	Memo     string
+	Note     string
+	Pending  bool
+	Tags     []string
+	Postings []Posting
+}
+
+// Journal is a set of entries ready to be written in any of the formats.
+type Journal struct {
+	BaseCurrency string
+	Entries      []*Entry
+	// Opened holds the first date each account is used on, for formats that
+	// require accounts to be declared.
+	Opened map[string]string
+}
+
+// Export writes all of the user's transactions to w in the given format.
+// opts are passed to ListTransactionsInUser, for example to limit the date
+// range.
+func Export(client *pocketsmith.Client, userID int, w io.Writer, format Format, opts ...pocketsmith.ListTransactionsOption) error {
+	journal, err := Build(client, userID, opts...)
+	if err != nil {
+		return err
+	}
+	return journal.Write(w, format)
+}
+
+// Build fetches the user's accounts, categories and transactions and turns
+// them into a Journal.
+func Build(client *pocketsmith.Client, userID int, opts ...pocketsmith.ListTransactionsOption) (*Journal, error) {
+	user, err := client.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	accounts, err := client.ListAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %w", err)
+	}
+
+	categories, err := client.ListCategories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	var transactions []*pocketsmith.DetailedTransaction
+	err = client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		transactions = append(transactions, page...)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return New(strings.ToUpper(user.BaseCurrencyCode), accounts, categories, transactions), nil
+}
+
+// New builds a Journal from already fetched data.
+//
+// Transaction accounts are named Assets:Institution:Account or
+// Liabilities:Institution:Account depending on the account type. Categories
+// become Expenses:Parent:Child, or Income:Parent:Child when the category's
+// transactions net to a positive amount. Transfers are paired with the
+// opposite leg in another account when one exists, and otherwise post
+// against Equity:Transfers.
+func New(baseCurrency string, accounts []*pocketsmith.Account, categories []*pocketsmith.Category, transactions []*pocketsmith.DetailedTransaction) *Journal {
+	n := newNamer(accounts, categories, transactions)
+	j := &Journal{BaseCurrency: baseCurrency, Opened: make(map[string]string)}
+
+	sorted := make([]*pocketsmith.DetailedTransaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Date < sorted[b].Date
+	})
+
+	paired := make(map[int64]bool)
+	for i, tx := range sorted {
+		if paired[tx.ID] {
+			continue
+		}
+
+		entry := &Entry{
+			Date:    tx.Date,
+			Payee:   tx.Payee,
+			Memo:    tx.Memo,
+			Note:    tx.Note,
+			Pending: tx.NeedsReview,
+			Tags:    tx.Labels,
+		}
+
+		own := j.posting(n.account(tx.TransactionAccount), tx)
+
+		var other Posting
+		switch {
+		case tx.IsTransfer:
+			if leg := findTransferLeg(sorted[i+1:], tx, paired); leg != nil {
+				paired[leg.ID] = true
+				other = j.posting(n.account(leg.TransactionAccount), leg)
+				entry.Tags = mergeTags(entry.Tags, leg.Labels)
+			} else {
+				other = j.balancing("Equity:Transfers", own)
+			}
+		default:
+			other = j.balancing(n.category(tx.Category, tx.Amount), own)
+		}
+
+		entry.Postings = []Posting{own, other}
+		for _, p := range entry.Postings {
+			if _, ok := j.Opened[p.Account]; !ok {
+				j.Opened[p.Account] = entry.Date
+			}
+		}
+
+		j.Entries = append(j.Entries, entry)
+	}
+
+	return j
+}
+
+// Write writes the journal in the given format.
+func (j *Journal) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatLedger, FormatHledger:
+		return j.writeLedger(w, format)
+	case FormatBeancount:
+		return j.writeBeancount(w)
+	}
+	return fmt.Errorf("unknown journal format %q", format)
+}
+
+func (j *Journal) posting(account string, tx *pocketsmith.DetailedTransaction) Posting {
+	p := Posting{Account: account, Amount: tx.Amount, Currency: j.BaseCurrency}
+	if tx.TransactionAccount != nil && tx.TransactionAccount.CurrencyCode != "" {
+		p.Currency = strings.ToUpper(tx.TransactionAccount.CurrencyCode)
+	}
+	if p.Currency != j.BaseCurrency && j.BaseCurrency != "" {
+		p.Price = math.Abs(tx.AmountInBaseCurrency)
+	}
+	return p
+}
+
+// balancing returns the posting that balances p, in the base currency when p
+// has a price.
+func (j *Journal) balancing(account string, p Posting) Posting {
+	if p.Price != 0 {
+		return Posting{Account: account, Amount: -math.Copysign(p.Price, p.Amount), Currency: j.BaseCurrency}
+	}
+	return Posting{Account: account, Amount: -p.Amount, Currency: p.Currency}
+}
+
+// amount formats a posting amount, with its total price in the base currency
+// when it has one. Ledger, hledger and Beancount share this syntax.
+func (j *Journal) amount(p Posting) string {
+	amount := fmt.Sprintf("%.2f %s", p.Amount, p.Currency)
+	if p.Price != 0 {
+		amount += fmt.Sprintf(" @@ %.2f %s", p.Price, j.BaseCurrency)
+	}
+	return amount
+}
+
+func findTransferLeg(candidates []*pocketsmith.DetailedTransaction, tx *pocketsmith.DetailedTransaction, paired map[int64]bool) *pocketsmith.DetailedTransaction {
+	date, err := time.Parse("2006-01-02", tx.Date)
+	if err != nil {
+		return nil
+	}
+
+	for _, leg := range candidates {
+		if !leg.IsTransfer || paired[leg.ID] || sameAccount(tx, leg) {
+			continue
+		}
+
+		legDate, err := time.Parse("2006-01-02", leg.Date)
+		if err != nil {
+			continue
+		}
+		if legDate.Sub(date) > transferWindow {
+			break
+		}
+
+		if math.Abs(tx.AmountInBaseCurrency+leg.AmountInBaseCurrency) < 0.005 {
+			return leg
+		}
+	}
+
+	return nil
+}
+
+func sameAccount(a, b *pocketsmith.DetailedTransaction) bool {
+	if a.TransactionAccount == nil || b.TransactionAccount == nil {
+		return a.TransactionAccount == b.TransactionAccount
+	}
+	return a.TransactionAccount.ID == b.TransactionAccount.ID
+}
+
+func mergeTags(a, b []string) []string {
+	tags := append([]string{}, a...)
+	for _, tag := range b {
+		found := false
+		for _, existing := range tags {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
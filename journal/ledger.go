@@ -0,0 +1,64 @@
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeLedger writes the journal in Ledger syntax. hledger reads the same
+// syntax, but expects tags written as "name:" rather than Ledger's ":name:".
+func (j *Journal) writeLedger(w io.Writer, format Format) error {
+	bw := bufio.NewWriter(w)
+
+	for i, entry := range j.Entries {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+
+		flag := "*"
+		if entry.Pending {
+			flag = "!"
+		}
+		fmt.Fprintf(bw, "%s %s %s\n", strings.ReplaceAll(entry.Date, "-", "/"), flag, singleLine(entry.Payee))
+
+		if entry.Memo != "" {
+			fmt.Fprintf(bw, "    ; %s\n", singleLine(entry.Memo))
+		}
+		if entry.Note != "" {
+			fmt.Fprintf(bw, "    ; %s\n", singleLine(entry.Note))
+		}
+		if tags := ledgerTags(entry.Tags, format); tags != "" {
+			fmt.Fprintf(bw, "    ; %s\n", tags)
+		}
+
+		for _, p := range entry.Postings {
+			fmt.Fprintf(bw, "    %-50s  %s\n", p.Account, j.amount(p))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func ledgerTags(tags []string, format Format) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+			return r == ' ' || r == ':' || r == ','
+		}), "-"))
+	}
+
+	if format == FormatHledger {
+		return strings.Join(names, ":, ") + ":"
+	}
+	return ":" + strings.Join(names, ":") + ":"
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
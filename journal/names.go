@@ -0,0 +1,118 @@
+package journal
+
+import (
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type namer struct {
+	accounts       map[int]*pocketsmith.Account
+	categoryPaths  map[int]string
+	categoryTotals map[int]float64
+}
+
+func newNamer(accounts []*pocketsmith.Account, categories []*pocketsmith.Category, transactions []*pocketsmith.DetailedTransaction) *namer {
+	n := &namer{
+		accounts:       make(map[int]*pocketsmith.Account),
+		categoryPaths:  make(map[int]string),
+		categoryTotals: make(map[int]float64),
+	}
+
+	for _, account := range accounts {
+		n.accounts[account.ID] = account
+	}
+
+	var walk func(prefix string, categories []*pocketsmith.Category)
+	walk = func(prefix string, categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			path := component(category.Title)
+			if prefix != "" {
+				path = prefix + ":" + path
+			}
+			n.categoryPaths[category.ID] = path
+			walk(path, category.Children)
+		}
+	}
+	walk("", categories)
+
+	for _, tx := range transactions {
+		if tx.Category != nil && !tx.IsTransfer {
+			n.categoryTotals[tx.Category.ID] += tx.AmountInBaseCurrency
+		}
+	}
+
+	return n
+}
+
+// account returns the hierarchical name of a transaction account.
+func (n *namer) account(ta *pocketsmith.TransactionAccount) string {
+	if ta == nil {
+		return "Assets:Unknown"
+	}
+
+	root := "Assets"
+	if isLiability(ta.Type) {
+		root = "Liabilities"
+	}
+
+	parts := []string{root}
+	if ta.Institution.Title != "" {
+		parts = append(parts, component(ta.Institution.Title))
+	}
+
+	account, ok := n.accounts[ta.AccountID]
+	switch {
+	case !ok:
+		parts = append(parts, component(ta.Name))
+	case len(account.TransactionAccounts) > 1:
+		parts = append(parts, component(account.Title), component(ta.Name))
+	default:
+		parts = append(parts, component(account.Title))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// category returns the income or expense account for a category. amount is
+// used for uncategorised transactions, which are split into
+// Income:Uncategorised and Expenses:Uncategorised by sign.
+func (n *namer) category(category *pocketsmith.Category, amount float64) string {
+	if category == nil {
+		if amount > 0 {
+			return "Income:Uncategorised"
+		}
+		return "Expenses:Uncategorised"
+	}
+
+	path, ok := n.categoryPaths[category.ID]
+	if !ok {
+		path = component(category.Title)
+	}
+
+	if n.categoryTotals[category.ID] > 0 {
+		return "Income:" + path
+	}
+	return "Expenses:" + path
+}
+
+func isLiability(accountType pocketsmith.AccountType) bool {
+	switch accountType {
+	case pocketsmith.AccountTypeCredits, pocketsmith.AccountTypeLoans,
+		pocketsmith.AccountTypeMortgage, pocketsmith.AccountTypeOtherLiability:
+		return true
+	}
+	return false
+}
+
+// component makes a title safe to use as one segment of an account name by
+// removing the separator and collapsing whitespace, since two spaces end an
+// account name in Ledger.
+func component(title string) string {
+	title = strings.ReplaceAll(title, ":", " ")
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return "Unnamed"
+	}
+	return title
+}
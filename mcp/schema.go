@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// Schema derives a JSON Schema from a Go type using its JSON field names.
+// Struct fields may carry a `description:"..."` tag, and fields without
+// omitempty are listed as required.
+func Schema(t reflect.Type) map[string]any {
+	return schema(t, make(map[reflect.Type]bool))
+}
+
+func schema(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	// Nil pointers, slices and maps encode as null.
+	s := nonNullSchema(t, visiting)
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if typ, ok := s["type"].(string); ok && t != rawMessageType {
+			s["type"] = []string{typ, "null"}
+		}
+	}
+	return s
+}
+
+func nonNullSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == rawMessageType {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schema(t.Elem(), visiting)}
+	case reflect.Struct:
+		// Recursive types such as Category.Children are described as plain
+		// objects below the first level.
+		if visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			property := schema(field.Type, visiting)
+			if description := field.Tag.Get("description"); description != "" {
+				property["description"] = description
+			}
+			properties[name] = property
+
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+
+		s := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+
+	return map[string]any{}
+}
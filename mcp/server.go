@@ -0,0 +1,285 @@
+// Package mcp implements a Model Context Protocol server that exposes
+// PocketSmith to LLM assistants as a set of tools.
+//
+// The server speaks JSON-RPC 2.0 over stdio (one message per line) or over
+// HTTP (one message per POST). Tool input and output schemas are derived from
+// the Go types of the tool arguments and of the library's models.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// ProtocolVersion is the MCP revision implemented by the server.
+const ProtocolVersion = "2025-06-18"
+
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	readOnly       bool
+	name           string
+	version        string
+	bearerToken    string
+	allowedHosts   []string
+	allowedOrigins []string
+}
+
+// WithReadOnly hides every tool that changes data.
+func WithReadOnly() ServerOption {
+	return func(o *serverOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithServerInfo sets the name and version reported to clients.
+func WithServerInfo(name, version string) ServerOption {
+	return func(o *serverOptions) {
+		o.name = name
+		o.version = version
+	}
+}
+
+// WithBearerToken sets the token HTTP clients must send in an
+// "Authorization: Bearer" header. ServeHTTP rejects every request until a
+// token is set.
+func WithBearerToken(token string) ServerOption {
+	return func(o *serverOptions) {
+		o.bearerToken = token
+	}
+}
+
+// WithAllowedHosts lets HTTP requests name these hosts in their Host header,
+// in addition to localhost and the loopback addresses. A host without a port
+// matches any port.
+func WithAllowedHosts(hosts ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.allowedHosts = append(o.allowedHosts, hosts...)
+	}
+}
+
+// WithAllowedOrigins lets browsers on these origins, such as
+// "https://example.com", call the server over HTTP. Pages served from
+// localhost and the loopback addresses are always allowed.
+func WithAllowedOrigins(origins ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.allowedOrigins = append(o.allowedOrigins, origins...)
+	}
+}
+
+// Tool is a function exposed to clients.
+type Tool struct {
+	Name         string
+	Description  string
+	ReadOnly     bool
+	InputSchema  map[string]any
+	OutputSchema map[string]any
+	call         func(ctx context.Context, arguments json.RawMessage) (any, error)
+}
+
+// Server handles MCP messages for a single PocketSmith user.
+type Server struct {
+	client  *pocketsmith.Client
+	userID  int
+	options *serverOptions
+	tools   []*Tool
+}
+
+// NewServer returns a Server whose tools act on the given user.
+func NewServer(client *pocketsmith.Client, userID int, opts ...ServerOption) *Server {
+	options := &serverOptions{name: "pocketsmith", version: "0.1.0"}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	s := &Server{client: client, userID: userID, options: options}
+	s.registerTools()
+	return s
+}
+
+// Tools returns the tools available to clients.
+func (s *Server) Tools() []*Tool {
+	var tools []*Tool
+	for _, tool := range s.tools {
+		if tool.ReadOnly || !s.options.readOnly {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// addTool registers a tool whose arguments decode into In and whose result is
+// returned to the client as structured content of type Out.
+func addTool[In, Out any](s *Server, name, description string, readOnly bool, fn func(ctx context.Context, in In) (Out, error)) {
+	var in In
+	var out Out
+
+	s.tools = append(s.tools, &Tool{
+		Name:         name,
+		Description:  description,
+		ReadOnly:     readOnly,
+		InputSchema:  Schema(reflect.TypeOf(in)),
+		OutputSchema: Schema(reflect.TypeOf(result[Out]{Result: out})),
+		call: func(ctx context.Context, arguments json.RawMessage) (any, error) {
+			var in In
+			if len(arguments) > 0 && string(arguments) != "null" {
+				if err := json.Unmarshal(arguments, &in); err != nil {
+					return nil, fmt.Errorf("invalid arguments: %w", err)
+				}
+			}
+
+			out, err := fn(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			return result[Out]{Result: out}, nil
+		},
+	})
+}
+
+// result wraps a tool's output, since structured content must be an object.
+type result[T any] struct {
+	Result T `json:"result"`
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Handle processes one JSON-RPC message and returns the encoded response, or
+// nil for notifications.
+func (s *Server) Handle(ctx context.Context, message []byte) []byte {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}})
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+
+	// Requests without an ID are notifications and get no response.
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	resp := response{JSONRPC: "2.0", ID: req.ID}
+	if rpcErr != nil {
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+
+	return encode(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, req *request) (any, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{codeInvalidRequest, "jsonrpc must be 2.0"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": s.options.name, "version": s.options.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := make([]map[string]any, 0, len(s.tools))
+		for _, tool := range s.Tools() {
+			tools = append(tools, map[string]any{
+				"name":         tool.Name,
+				"description":  tool.Description,
+				"inputSchema":  tool.InputSchema,
+				"outputSchema": tool.OutputSchema,
+				"annotations":  map[string]any{"readOnlyHint": tool.ReadOnly},
+			})
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	}
+
+	if len(req.ID) == 0 {
+		// Unknown notifications, such as notifications/initialized, are ignored.
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method %q not found", req.Method)}
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, *rpcError) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+
+	var tool *Tool
+	for _, t := range s.Tools() {
+		if t.Name == call.Name {
+			tool = t
+			break
+		}
+	}
+	if tool == nil {
+		return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown tool %q", call.Name)}
+	}
+
+	// Tool failures are reported in the result so the model can see them.
+	out, err := tool.call(ctx, call.Arguments)
+	if err != nil {
+		return map[string]any{
+			"content": []map[string]any{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}, nil
+	}
+
+	text, err := json.Marshal(out)
+	if err != nil {
+		return nil, &rpcError{codeInternalError, err.Error()}
+	}
+
+	return map[string]any{
+		"content":           []map[string]any{{"type": "text", "text": string(text)}},
+		"structuredContent": out,
+		"isError":           false,
+	}, nil
+}
+
+func encode(resp response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{codeInternalError, err.Error()}})
+	}
+	return data
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type noArguments struct{}
+
+type searchTransactionsArguments struct {
+	Query                string `json:"query,omitempty" description:"Text to search for in payees, memos and notes"`
+	StartDate            string `json:"start_date,omitempty" description:"Only transactions on or after this date, YYYY-MM-DD"`
+	EndDate              string `json:"end_date,omitempty" description:"Only transactions on or before this date, YYYY-MM-DD"`
+	NeedsReview          bool   `json:"needs_review,omitempty" description:"Only transactions that need review"`
+	Uncategorised        bool   `json:"uncategorised,omitempty" description:"Only transactions without a category"`
+	TransactionAccountID int    `json:"transaction_account_id,omitempty" description:"Only transactions in this transaction account"`
+	Page                 int    `json:"page,omitempty" description:"Page of results, starting at 1"`
+}
+
+type categoriseTransactionArguments struct {
+	TransactionID int64 `json:"transaction_id" description:"ID of the transaction to categorise"`
+	CategoryID    int   `json:"category_id" description:"ID of the category, or -1 to remove the category"`
+}
+
+type labelTransactionArguments struct {
+	TransactionID int64    `json:"transaction_id" description:"ID of the transaction to label"`
+	Labels        []string `json:"labels" description:"Labels to add to the transaction"`
+	Replace       bool     `json:"replace,omitempty" description:"Replace the existing labels instead of adding to them"`
+}
+
+type getBudgetArguments struct {
+	RollUp bool `json:"roll_up,omitempty" description:"Roll sub-category budgets up into their parents"`
+}
+
+type uploadAttachmentArguments struct {
+	Title         string `json:"title" description:"Title of the attachment"`
+	FileName      string `json:"file_name" description:"File name including extension"`
+	FileData      string `json:"file_data" description:"Base64 encoded file contents"`
+	TransactionID int64  `json:"transaction_id,omitempty" description:"Transaction to attach the file to"`
+}
+
+func (s *Server) registerTools() {
+	addTool(s, "list_accounts", "List the user's accounts with their balances.", true,
+		func(ctx context.Context, _ noArguments) ([]*pocketsmith.Account, error) {
+			return s.client.ListAccounts(s.userID)
+		})
+
+	addTool(s, "list_categories", "List the user's categories. Sub-categories are nested under children.", true,
+		func(ctx context.Context, _ noArguments) ([]*pocketsmith.Category, error) {
+			return s.client.ListCategories(s.userID)
+		})
+
+	addTool(s, "search_transactions", "Search the user's transactions.", true,
+		func(ctx context.Context, args searchTransactionsArguments) ([]*pocketsmith.DetailedTransaction, error) {
+			var opts []pocketsmith.ListTransactionsOption
+			if args.Query != "" {
+				opts = append(opts, pocketsmith.WithSearch(args.Query))
+			}
+			if args.StartDate != "" {
+				opts = append(opts, pocketsmith.WithStartDate(args.StartDate))
+			}
+			if args.EndDate != "" {
+				opts = append(opts, pocketsmith.WithEndDate(args.EndDate))
+			}
+			if args.NeedsReview {
+				opts = append(opts, pocketsmith.WithNeedsReview(1))
+			}
+			if args.Uncategorised {
+				opts = append(opts, pocketsmith.WithUncategorised(1))
+			}
+			if args.Page > 0 {
+				opts = append(opts, pocketsmith.WithPage(args.Page))
+			}
+
+			if args.TransactionAccountID != 0 {
+				return s.client.ListTransactionsInTransactionAccount(args.TransactionAccountID, opts...)
+			}
+			return s.client.ListTransactionsInUser(s.userID, opts...)
+		})
+
+	addTool(s, "get_budget", "Get the user's budget, with actual and forecast amounts per category and period.", true,
+		func(ctx context.Context, args getBudgetArguments) ([]*pocketsmith.BudgetAnalysisPackage, error) {
+			return s.client.ListBudget(s.userID, args.RollUp)
+		})
+
+	addTool(s, "categorise_transaction", "Set the category of a transaction and mark it as reviewed.", false,
+		func(ctx context.Context, args categoriseTransactionArguments) (*pocketsmith.DetailedTransaction, error) {
+			// UpdateTransaction leaves out a zero category, which would only
+			// mark the transaction as reviewed.
+			if args.CategoryID == 0 {
+				return nil, errors.New("category_id is required; use -1 to remove the category")
+			}
+
+			existing, err := s.client.GetTransaction(args.TransactionID)
+			if err != nil {
+				return nil, err
+			}
+
+			tx := existing.Transaction()
+			tx.CategoryID = pocketsmith.CategoryID(args.CategoryID)
+			tx.NeedsReview = false
+
+			return s.client.UpdateTransaction(args.TransactionID, tx)
+		})
+
+	addTool(s, "label_transaction", "Add labels to a transaction.", false,
+		func(ctx context.Context, args labelTransactionArguments) (*pocketsmith.DetailedTransaction, error) {
+			existing, err := s.client.GetTransaction(args.TransactionID)
+			if err != nil {
+				return nil, err
+			}
+
+			tx := existing.Transaction()
+			if args.Replace {
+				tx.Labels = nil
+			}
+			for _, label := range args.Labels {
+				found := false
+				for _, existing := range tx.Labels {
+					if existing == label {
+						found = true
+						break
+					}
+				}
+				if !found {
+					tx.Labels = append(tx.Labels, label)
+				}
+			}
+
+			// UpdateTransaction leaves out empty labels, so replacing them with
+			// none would keep them.
+			return s.client.ReplaceTransaction(args.TransactionID, tx)
+		})
+
+	addTool(s, "upload_attachment", "Upload a file as an attachment, optionally attaching it to a transaction.", false,
+		func(ctx context.Context, args uploadAttachmentArguments) (*pocketsmith.Attachment, error) {
+			if args.FileData == "" {
+				return nil, errors.New("file_data is required")
+			}
+
+			attachment, err := s.client.CreateAttachment(s.userID, &pocketsmith.CreateAttachment{
+				Title:    args.Title,
+				FileName: args.FileName,
+				FileData: args.FileData,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if args.TransactionID != 0 {
+				if err := s.client.AssignToTransaction(args.TransactionID, attachment.ID); err != nil {
+					return nil, err
+				}
+			}
+
+			return attachment, nil
+		})
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ServeStdio reads newline-delimited messages from r and writes responses to
+// w until r is exhausted or ctx is done.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if resp := s.Handle(ctx, line); resp != nil {
+			if _, err := w.Write(append(resp, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ServeHTTP implements the HTTP transport: each POST carries one message and
+// the response is returned as the body. Notifications are acknowledged with
+// 202 Accepted.
+//
+// Requests must carry the token set with WithBearerToken. To guard against
+// DNS rebinding, the Host header must name a loopback address or a host set
+// with WithAllowedHosts, and an Origin header, when present, must be a
+// loopback origin or one set with WithAllowedOrigins.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, "host not allowed", http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !s.allowedOrigin(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 16*1024*1024))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := s.Handle(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// authorized reports whether the request carries the configured bearer
+// token. Without a configured token nothing is authorized.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.options.bearerToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.options.bearerToken)) == 1
+}
+
+func (s *Server) allowedHost(host string) bool {
+	if isLoopback(host) {
+		return true
+	}
+	for _, allowed := range s.options.allowedHosts {
+		if strings.EqualFold(host, allowed) || strings.EqualFold(hostname(host), allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) allowedOrigin(origin string) bool {
+	for _, allowed := range s.options.allowedOrigins {
+		if strings.EqualFold(origin, strings.TrimSuffix(allowed, "/")) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return isLoopback(u.Host)
+}
+
+// isLoopback reports whether host, with or without a port, is localhost or a
+// loopback address.
+func isLoopback(host string) bool {
+	name := hostname(host)
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostname strips the port and IPv6 brackets from host.
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
@@ -0,0 +1,205 @@
+// Package metrics exports PocketSmith balances and API client statistics in
+// the Prometheus text format, for graphing net worth and account balances in
+// tools such as Grafana.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type ExporterOption func(*Exporter)
+
+// WithRefreshInterval sets how often Run refreshes the balances. The default
+// is five minutes.
+func WithRefreshInterval(interval time.Duration) ExporterOption {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// Exporter periodically fetches a user's accounts and transaction accounts and
+// serves their balances on /metrics. It also serves request metrics for
+// clients that use its Transport.
+type Exporter struct {
+	client   *pocketsmith.Client
+	userID   int
+	interval time.Duration
+	stats    *apiStats
+
+	mu          sync.Mutex
+	snapshot    []*family
+	lastRefresh time.Time
+	lastErr     error
+}
+
+// NewExporter returns an Exporter for the user. To collect API metrics, create
+// the client with Transport:
+//
+//	exporter := metrics.NewExporter(nil, 0)
+//	client := pocketsmith.NewClient(token, pocketsmith.WithHTTPClient(&http.Client{
+//		Transport: exporter.Transport(nil),
+//	}))
+//	exporter.SetClient(client, userID)
+func NewExporter(client *pocketsmith.Client, userID int, opts ...ExporterOption) *Exporter {
+	e := &Exporter{client: client, userID: userID, interval: 5 * time.Minute, stats: newAPIStats()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// SetClient sets the client and user used to fetch balances.
+func (e *Exporter) SetClient(client *pocketsmith.Client, userID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client = client
+	e.userID = userID
+}
+
+// Transport wraps base, or http.DefaultTransport when base is nil, to record
+// the latency, status and errors of every request.
+func (e *Exporter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, stats: e.stats}
+}
+
+// Run refreshes the balances immediately and then every refresh interval
+// until ctx is done. Errors are reported through the
+// pocketsmith_refresh_success metric.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.Refresh()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches the balances now.
+func (e *Exporter) Refresh() error {
+	e.mu.Lock()
+	client, userID := e.client, e.userID
+	e.mu.Unlock()
+
+	families, err := e.collect(client, userID)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastErr = err
+	if err == nil {
+		e.snapshot = families
+		e.lastRefresh = time.Now()
+	}
+
+	return err
+}
+
+func (e *Exporter) collect(client *pocketsmith.Client, userID int) ([]*family, error) {
+	if client == nil {
+		return nil, fmt.Errorf("no client set")
+	}
+
+	accounts, err := client.ListAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %w", err)
+	}
+
+	transactionAccounts, err := client.ListTransactionAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transaction accounts: %w", err)
+	}
+
+	needsReview := 0
+	err = client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		needsReview += len(page)
+		return nil
+	}, pocketsmith.WithNeedsReview(1))
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	accountBalance := &family{name: "pocketsmith_account_current_balance", help: "Current balance of an account in its own currency.", kind: "gauge"}
+	accountBase := &family{name: "pocketsmith_account_current_balance_in_base_currency", help: "Current balance of an account in the user's base currency.", kind: "gauge"}
+	accountSafe := &family{name: "pocketsmith_account_safe_balance", help: "Safe balance of an account in its own currency.", kind: "gauge"}
+	for _, account := range accounts {
+		labels := map[string]string{
+			"account_id":  strconv.Itoa(account.ID),
+			"account":     account.Title,
+			"institution": account.PrimaryTransactionAccount.Institution.Title,
+			"type":        string(account.Type),
+			"currency":    account.CurrencyCode,
+			"net_worth":   strconv.FormatBool(account.IsNetWorth),
+		}
+		accountBalance.samples = append(accountBalance.samples, sample{labels: labels, value: account.CurrentBalance})
+		accountBase.samples = append(accountBase.samples, sample{labels: labels, value: account.CurrentBalanceInBaseCurrency})
+		accountSafe.samples = append(accountSafe.samples, sample{labels: labels, value: account.SafeBalance})
+	}
+
+	taBalance := &family{name: "pocketsmith_transaction_account_current_balance", help: "Current balance of a transaction account in its own currency.", kind: "gauge"}
+	taBase := &family{name: "pocketsmith_transaction_account_current_balance_in_base_currency", help: "Current balance of a transaction account in the user's base currency.", kind: "gauge"}
+	taSafe := &family{name: "pocketsmith_transaction_account_safe_balance", help: "Safe balance of a transaction account in its own currency.", kind: "gauge"}
+	for _, ta := range transactionAccounts {
+		labels := map[string]string{
+			"transaction_account_id": strconv.Itoa(ta.ID),
+			"account_id":             strconv.Itoa(ta.AccountID),
+			"account":                ta.Name,
+			"institution":            ta.Institution.Title,
+			"type":                   string(ta.Type),
+			"currency":               ta.CurrencyCode,
+		}
+		taBalance.samples = append(taBalance.samples, sample{labels: labels, value: ta.CurrentBalance})
+		taBase.samples = append(taBase.samples, sample{labels: labels, value: ta.CurrentBalanceInBaseCurrency})
+		taSafe.samples = append(taSafe.samples, sample{labels: labels, value: ta.SafeBalance})
+	}
+
+	review := &family{
+		name:    "pocketsmith_transactions_needing_review",
+		help:    "Number of transactions that need review.",
+		kind:    "gauge",
+		samples: []sample{{value: float64(needsReview)}},
+	}
+
+	return []*family{accountBalance, accountBase, accountSafe, taBalance, taBase, taSafe, review}, nil
+}
+
+// ServeHTTP writes the metrics from the last successful refresh along with the
+// API request metrics.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	families := append([]*family{}, e.snapshot...)
+
+	success := 1.0
+	if e.lastErr != nil {
+		success = 0
+	}
+	families = append(families,
+		&family{name: "pocketsmith_refresh_success", help: "Whether the last refresh of balances succeeded.", kind: "gauge", samples: []sample{{value: success}}},
+	)
+	if !e.lastRefresh.IsZero() {
+		families = append(families,
+			&family{name: "pocketsmith_last_refresh_timestamp_seconds", help: "Time of the last successful refresh.", kind: "gauge", samples: []sample{{value: float64(e.lastRefresh.Unix())}}},
+		)
+	}
+	e.mu.Unlock()
+
+	families = append(families, e.stats.families()...)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeFamilies(w, families)
+}
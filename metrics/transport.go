@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the request duration
+// histogram.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type requestKey struct {
+	method   string
+	endpoint string
+}
+
+type requestStats struct {
+	statuses map[string]float64
+	errors   float64
+	buckets  []float64
+	count    float64
+	sum      float64
+}
+
+// apiStats collects request metrics from an instrumented transport.
+type apiStats struct {
+	mu       sync.Mutex
+	requests map[requestKey]*requestStats
+}
+
+func newAPIStats() *apiStats {
+	return &apiStats{requests: make(map[requestKey]*requestStats)}
+}
+
+type transport struct {
+	base  http.RoundTripper
+	stats *apiStats
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.stats.observe(req, resp, err, time.Since(start))
+	return resp, err
+}
+
+func (a *apiStats) observe(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	key := requestKey{method: req.Method, endpoint: pocketsmith.Route(req)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats, ok := a.requests[key]
+	if !ok {
+		stats = &requestStats{statuses: make(map[string]float64), buckets: make([]float64, len(durationBuckets))}
+		a.requests[key] = stats
+	}
+
+	seconds := duration.Seconds()
+	stats.count++
+	stats.sum += seconds
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+
+	if err != nil {
+		stats.errors++
+		return
+	}
+
+	stats.statuses[strconv.Itoa(resp.StatusCode)]++
+	if resp.StatusCode >= 400 {
+		stats.errors++
+	}
+}
+
+func (a *apiStats) families() []*family {
+	requests := &family{name: "pocketsmith_api_requests_total", help: "API requests by endpoint and HTTP status.", kind: "counter"}
+	errors := &family{name: "pocketsmith_api_request_errors_total", help: "API requests that failed or returned an error status.", kind: "counter"}
+	durations := &family{name: "pocketsmith_api_request_duration_seconds", help: "API request latency.", kind: "histogram"}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, stats := range a.requests {
+		labels := map[string]string{"method": key.method, "endpoint": key.endpoint}
+
+		for status, count := range stats.statuses {
+			requests.samples = append(requests.samples, sample{labels: withLabel(labels, "status", status), value: count})
+		}
+		errors.samples = append(errors.samples, sample{labels: labels, value: stats.errors})
+
+		for i, bound := range durationBuckets {
+			durations.samples = append(durations.samples, sample{suffix: "_bucket", labels: withLabel(labels, "le", formatValue(bound)), value: stats.buckets[i]})
+		}
+		durations.samples = append(durations.samples,
+			sample{suffix: "_bucket", labels: withLabel(labels, "le", "+Inf"), value: stats.count},
+			sample{suffix: "_sum", labels: labels, value: stats.sum},
+			sample{suffix: "_count", labels: labels, value: stats.count},
+		)
+	}
+
+	return []*family{requests, errors, durations}
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	copied := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
@@ -0,0 +1,150 @@
+// Package payees cleans up the noisy payees bank feeds produce, such as
+// "SQ *COFFEE 1234 SYDNEY", and suggests category rules for the cleaned
+// payees.
+package payees
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CaseMode is how a normalised payee is capitalised.
+type CaseMode string
+
+const (
+	// CaseKeep leaves capitalisation alone.
+	CaseKeep  CaseMode = "keep"
+	CaseLower CaseMode = "lower"
+	CaseUpper CaseMode = "upper"
+	// CaseTitle capitalises the first letter of each word.
+	CaseTitle CaseMode = "title"
+)
+
+// DefaultStrip removes card processor prefixes, reference and card numbers,
+// dates and stray asterisks.
+var DefaultStrip = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(sq|sp|tst|pp|paypal|zettle|sumup|ls)\s*\*\s*`),
+	regexp.MustCompile(`(?i)\bcard\s*(x+|\*+)?\d{4}\b`),
+	regexp.MustCompile(`(?i)^\s*(pos|eftpos|visa|debit card|card)( purchase)?\s+`),
+	regexp.MustCompile(`\b\d{2}[/.-]\d{2}([/.-]\d{2,4})?\b`),
+	regexp.MustCompile(`#?\b\d{3,}\b`),
+	regexp.MustCompile(`\s\*\s*`),
+}
+
+// Alias maps payees matching Pattern to the merchant Name.
+type Alias struct {
+	Pattern *regexp.Regexp
+	Name    string
+}
+
+// Normalizer cleans payees: it strips everything matching Strip, returns the
+// name of the first alias matching the result, and otherwise collapses
+// whitespace and applies Case.
+type Normalizer struct {
+	Strip   []*regexp.Regexp
+	Aliases []Alias
+	Case    CaseMode
+}
+
+// NewNormalizer returns a Normalizer with DefaultStrip and title case.
+func NewNormalizer(aliases ...Alias) *Normalizer {
+	return &Normalizer{Strip: DefaultStrip, Aliases: aliases, Case: CaseTitle}
+}
+
+// Config is the JSON form of a Normalizer. Patterns are Go regular
+// expressions; aliases are matched case-insensitively.
+//
+//	{
+//	  "strip": ["\\bSYDNEY$"],
+//	  "aliases": [{"match": "coffee", "name": "Corner Coffee"}],
+//	  "case": "title",
+//	  "default_strip": true
+//	}
+type Config struct {
+	Strip   []string `json:"strip"`
+	Aliases []struct {
+		Match string `json:"match"`
+		Name  string `json:"name"`
+	} `json:"aliases"`
+	Case CaseMode `json:"case"`
+	// DefaultStrip adds DefaultStrip before Strip.
+	DefaultStrip bool `json:"default_strip"`
+}
+
+// LoadConfig reads a Config and compiles it into a Normalizer.
+func LoadConfig(r io.Reader) (*Normalizer, error) {
+	var config Config
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("error decoding config: %w", err)
+	}
+
+	n := &Normalizer{Case: config.Case}
+	if config.DefaultStrip {
+		n.Strip = append(n.Strip, DefaultStrip...)
+	}
+	for _, pattern := range config.Strip {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip pattern %q: %w", pattern, err)
+		}
+		n.Strip = append(n.Strip, re)
+	}
+	for _, alias := range config.Aliases {
+		re, err := regexp.Compile("(?i)" + alias.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias pattern %q: %w", alias.Match, err)
+		}
+		n.Aliases = append(n.Aliases, Alias{Pattern: re, Name: alias.Name})
+	}
+
+	return n, nil
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Normalize returns the cleaned payee. If stripping leaves nothing, the
+// trimmed original is returned.
+func (n *Normalizer) Normalize(payee string) string {
+	s := payee
+	for _, re := range n.Strip {
+		s = re.ReplaceAllString(s, " ")
+	}
+	s = strings.Trim(whitespace.ReplaceAllString(s, " "), " -*,.")
+
+	for _, alias := range n.Aliases {
+		if alias.Pattern.MatchString(s) || alias.Pattern.MatchString(payee) {
+			return alias.Name
+		}
+	}
+
+	if s == "" {
+		return strings.TrimSpace(payee)
+	}
+
+	switch n.Case {
+	case CaseLower:
+		s = strings.ToLower(s)
+	case CaseUpper:
+		s = strings.ToUpper(s)
+	case CaseTitle:
+		s = titleCase(s)
+	}
+
+	return s
+}
+
+func titleCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	start := true
+	for i, r := range runes {
+		if start && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		start = unicode.IsSpace(r) || r == '-' || r == '/' || r == '('
+	}
+	return string(runes)
+}
@@ -0,0 +1,219 @@
+package payees
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Change is a proposed payee update for a transaction.
+type Change struct {
+	Transaction *pocketsmith.DetailedTransaction `json:"transaction"`
+	From        string                           `json:"from"`
+	To          string                           `json:"to"`
+}
+
+// Preview returns the changes normalising the transactions would make.
+// OriginalPayee is normalised when set, so running it again after editing
+// the rules starts from the bank's payee rather than a previous result.
+func (n *Normalizer) Preview(transactions []*pocketsmith.DetailedTransaction) []*Change {
+	var changes []*Change
+	for _, tx := range transactions {
+		source := tx.OriginalPayee
+		if source == "" {
+			source = tx.Payee
+		}
+
+		if cleaned := n.Normalize(source); cleaned != tx.Payee {
+			changes = append(changes, &Change{Transaction: tx, From: tx.Payee, To: cleaned})
+		}
+	}
+	return changes
+}
+
+// Apply saves the changes with UpdateTransaction. It returns the number of
+// transactions updated before any error.
+func Apply(client *pocketsmith.Client, changes []*Change) (int, error) {
+	for i, change := range changes {
+		update := change.Transaction.Transaction()
+		update.Payee = change.To
+		if _, err := client.UpdateTransaction(change.Transaction.ID, update); err != nil {
+			return i, fmt.Errorf("error updating transaction %d: %w", change.Transaction.ID, err)
+		}
+	}
+	return len(changes), nil
+}
+
+const (
+	// DefaultMinCount is used when RuleOptions.MinCount is zero.
+	DefaultMinCount = 3
+	// DefaultMinShare is used when RuleOptions.MinShare is zero.
+	DefaultMinShare = 0.8
+)
+
+// RuleOptions controls which category rules are suggested.
+type RuleOptions struct {
+	// MinCount is how many categorised transactions a payee needs.
+	MinCount int
+	// MinShare is the fraction of those that must be in the same category.
+	MinShare float64
+	// Existing rules; payees they already match are skipped.
+	Existing []*pocketsmith.CategoryRule
+}
+
+// RuleSuggestion is a category rule for the transactions sharing a
+// normalised payee.
+type RuleSuggestion struct {
+	// PayeeMatches is the longest text found in the bank's payee of every
+	// transaction in the group, which is what rules are matched against.
+	PayeeMatches string `json:"payee_matches"`
+	// Payee is the normalised payee, for display.
+	Payee    string                `json:"payee"`
+	Category *pocketsmith.Category `json:"category"`
+	// Count is the number of categorised transactions with the payee, and
+	// Share the fraction of them in Category.
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// minMatchLength is the shortest PayeeMatches suggested, since shorter text
+// would match unrelated payees.
+const minMatchLength = 3
+
+// SuggestRules suggests a category rule for each normalised payee whose
+// categorised transactions are mostly in one non-transfer category. Payees
+// without enough text in common across their transactions to match them all
+// are skipped. Suggestions are sorted by count, most common first.
+func (n *Normalizer) SuggestRules(transactions []*pocketsmith.DetailedTransaction, opts RuleOptions) []*RuleSuggestion {
+	minCount := opts.MinCount
+	if minCount == 0 {
+		minCount = DefaultMinCount
+	}
+	minShare := opts.MinShare
+	if minShare == 0 {
+		minShare = DefaultMinShare
+	}
+
+	type tally struct {
+		total      int
+		counts     map[int]int
+		categories map[int]*pocketsmith.Category
+		sources    []string
+	}
+	tallies := make(map[string]*tally)
+	for _, tx := range transactions {
+		if tx.Category == nil || tx.Category.IsTransfer || tx.IsTransfer {
+			continue
+		}
+		source := tx.OriginalPayee
+		if source == "" {
+			source = tx.Payee
+		}
+		payee := n.Normalize(source)
+		t, ok := tallies[payee]
+		if !ok {
+			t = &tally{counts: make(map[int]int), categories: make(map[int]*pocketsmith.Category)}
+			tallies[payee] = t
+		}
+		t.total++
+		t.counts[tx.Category.ID]++
+		t.categories[tx.Category.ID] = tx.Category
+		t.sources = append(t.sources, source)
+	}
+
+	var suggestions []*RuleSuggestion
+payees:
+	for payee, t := range tallies {
+		if t.total < minCount {
+			continue
+		}
+		for _, rule := range opts.Existing {
+			if rule.PayeeMatches == "" {
+				continue
+			}
+			for _, source := range t.sources {
+				if strings.Contains(strings.ToLower(source), strings.ToLower(rule.PayeeMatches)) {
+					continue payees
+				}
+			}
+		}
+
+		best, count := 0, 0
+		for id, c := range t.counts {
+			if c > count || (c == count && id < best) {
+				best, count = id, c
+			}
+		}
+		share := float64(count) / float64(t.total)
+		if share < minShare {
+			continue
+		}
+
+		matches := commonSubstring(t.sources)
+		if len(matches) < minMatchLength {
+			continue
+		}
+
+		suggestions = append(suggestions, &RuleSuggestion{
+			PayeeMatches: matches,
+			Payee:        payee,
+			Category:     t.categories[best],
+			Count:        t.total,
+			Share:        share,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].PayeeMatches < suggestions[j].PayeeMatches
+	})
+
+	return suggestions
+}
+
+// commonSubstring returns the longest text contained in every value, with
+// surrounding spaces and asterisks trimmed. Ties go to the earliest in the
+// first value.
+func commonSubstring(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	first := values[0]
+	for length := len(first); length > 0; length-- {
+	starts:
+		for start := 0; start+length <= len(first); start++ {
+			candidate := strings.Trim(first[start:start+length], " *")
+			if len(candidate) < length || !utf8.ValidString(candidate) {
+				// A trimmed candidate is found again at a shorter length.
+				continue
+			}
+			for _, value := range values[1:] {
+				if !strings.Contains(value, candidate) {
+					continue starts
+				}
+			}
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// CreateRules creates a category rule for each suggestion.
+func CreateRules(client *pocketsmith.Client, suggestions []*RuleSuggestion) ([]*pocketsmith.CategoryRule, error) {
+	var rules []*pocketsmith.CategoryRule
+	for _, s := range suggestions {
+		rule, err := client.CreateCategoryRule(s.Category.ID, s.PayeeMatches)
+		if err != nil {
+			return rules, fmt.Errorf("error creating rule for %s: %w", s.PayeeMatches, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
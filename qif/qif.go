@@ -0,0 +1,89 @@
+// Package qif reads and writes Quicken Interchange Format files.
+//
+// Only the bank, cash and credit card sections are supported. Investment,
+// memorised transaction and category list sections are skipped when reading.
+package qif
+
+import (
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Section types as they appear in a "!Type:" header.
+const (
+	TypeBank  = "Bank"
+	TypeCash  = "Cash"
+	TypeCCard = "CCard"
+)
+
+// Split is one line of a split transaction.
+type Split struct {
+	Category string
+	Memo     string
+	Amount   float64
+}
+
+// Record is a single transaction read from a QIF file.
+type Record struct {
+	Date     string // YYYY-MM-DD
+	Amount   float64
+	Payee    string
+	Memo     string
+	Number   string
+	Category string
+	Cleared  string
+	Splits   []Split
+}
+
+// Section is a run of records under a single "!Type:" header. Account is set
+// when the section is preceded by an "!Account" block.
+type Section struct {
+	Type    string
+	Account string
+	Records []*Record
+}
+
+// IsCleared reports whether the record was marked as cleared or reconciled.
+func (r *Record) IsCleared() bool {
+	switch strings.ToUpper(r.Cleared) {
+	case "*", "C", "X", "R":
+		return true
+	}
+	return false
+}
+
+// transferAccount returns the account name if category is a QIF transfer
+// reference such as "[Savings]".
+func transferAccount(category string) (string, bool) {
+	if strings.HasPrefix(category, "[") && strings.HasSuffix(category, "]") {
+		return category[1 : len(category)-1], true
+	}
+	return "", false
+}
+
+// CategoryIndex maps category titles to IDs, including sub-categories. Titles
+// are also indexed in QIF's "Parent:Child" form.
+func CategoryIndex(categories []*pocketsmith.Category) map[string]pocketsmith.CategoryID {
+	index := make(map[string]pocketsmith.CategoryID)
+
+	var walk func(prefix string, categories []*pocketsmith.Category)
+	walk = func(prefix string, categories []*pocketsmith.Category) {
+		for _, category := range categories {
+			if _, ok := index[category.Title]; !ok {
+				index[category.Title] = pocketsmith.CategoryID(category.ID)
+			}
+
+			path := category.Title
+			if prefix != "" {
+				path = prefix + ":" + category.Title
+				index[path] = pocketsmith.CategoryID(category.ID)
+			}
+
+			walk(path, category.Children)
+		}
+	}
+	walk("", categories)
+
+	return index
+}
@@ -0,0 +1,301 @@
+package qif
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	dayFirst bool
+}
+
+// WithDayFirst parses dates as DD/MM/YYYY instead of the default MM/DD/YYYY.
+func WithDayFirst() ParseOption {
+	return func(o *parseOptions) {
+		o.dayFirst = true
+	}
+}
+
+// Parse reads all bank, cash and credit card sections from a QIF file.
+func Parse(r io.Reader, opts ...ParseOption) ([]*Section, error) {
+	options := &parseOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var (
+		sections []*Section
+		section  *Section
+		record   *Record
+		account  string
+		// inAccount is set while reading an "!Account" block, skip is set while
+		// reading a section type that isn't supported.
+		inAccount bool
+		skip      bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.TrimSpace(line)
+			switch {
+			case strings.EqualFold(header, "!Account"):
+				inAccount = true
+				account = ""
+			case strings.HasPrefix(strings.ToLower(header), "!type:"):
+				inAccount = false
+				sectionType := strings.TrimSpace(header[len("!type:"):])
+				switch strings.ToLower(sectionType) {
+				case "bank", "cash", "ccard":
+					skip = false
+					section = &Section{Type: normaliseType(sectionType), Account: account}
+					sections = append(sections, section)
+				default:
+					skip = true
+					section = nil
+				}
+			default:
+				// Options such as !Option:AutoSwitch carry no data.
+			}
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+
+		if inAccount {
+			switch code {
+			case 'N':
+				account = value
+			case '^':
+				inAccount = false
+			}
+			continue
+		}
+
+		if skip || section == nil {
+			continue
+		}
+
+		if code == '^' {
+			if record != nil {
+				section.Records = append(section.Records, record)
+				record = nil
+			}
+			continue
+		}
+
+		if record == nil {
+			record = &Record{}
+		}
+
+		switch code {
+		case 'D':
+			date, err := parseDate(value, options.dayFirst)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			record.Date = date
+		case 'T', 'U':
+			amount, err := parseAmount(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			record.Amount = amount
+		case 'P':
+			record.Payee = value
+		case 'M':
+			record.Memo = value
+		case 'N':
+			record.Number = value
+		case 'L':
+			record.Category = stripClass(value)
+		case 'C':
+			record.Cleared = value
+		case 'S':
+			record.Splits = append(record.Splits, Split{Category: stripClass(value)})
+		case 'E':
+			if n := len(record.Splits); n > 0 {
+				record.Splits[n-1].Memo = value
+			}
+		case '$':
+			amount, err := parseAmount(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if n := len(record.Splits); n > 0 {
+				record.Splits[n-1].Amount = amount
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The final record of a file is not always terminated.
+	if record != nil && section != nil {
+		section.Records = append(section.Records, record)
+	}
+
+	return sections, nil
+}
+
+// Transactions converts a record into transactions ready for AddTransaction.
+// Split records are flattened into one transaction per split. Categories are
+// looked up by title in categories; transfers to another QIF account are
+// flagged with IsTransfer. Records that are not cleared are marked as needing
+// review.
+func (r *Record) Transactions(categories map[string]pocketsmith.CategoryID) []*pocketsmith.Transaction {
+	newTransaction := func(category, memo string, amount float64) *pocketsmith.Transaction {
+		tx := &pocketsmith.Transaction{
+			Payee:        r.Payee,
+			Amount:       amount,
+			Date:         r.Date,
+			Memo:         memo,
+			ChequeNumber: r.Number,
+			NeedsReview:  !r.IsCleared(),
+		}
+
+		if _, ok := transferAccount(category); ok {
+			tx.IsTransfer = true
+		} else if id, ok := categories[category]; ok {
+			tx.CategoryID = id
+		}
+
+		return tx
+	}
+
+	if len(r.Splits) == 0 {
+		return []*pocketsmith.Transaction{newTransaction(r.Category, r.Memo, r.Amount)}
+	}
+
+	transactions := make([]*pocketsmith.Transaction, 0, len(r.Splits))
+	for _, split := range r.Splits {
+		memo := split.Memo
+		if memo == "" {
+			memo = r.Memo
+		}
+		transactions = append(transactions, newTransaction(split.Category, memo, split.Amount))
+	}
+
+	return transactions
+}
+
+// Transactions converts every record in the section. See Record.Transactions.
+func (s *Section) Transactions(categories map[string]pocketsmith.CategoryID) []*pocketsmith.Transaction {
+	var transactions []*pocketsmith.Transaction
+	for _, record := range s.Records {
+		transactions = append(transactions, record.Transactions(categories)...)
+	}
+	return transactions
+}
+
+// ReadTransactions parses a QIF file and converts all of its records into
+// transactions, mapping category titles using the user's categories.
+func ReadTransactions(client *pocketsmith.Client, userID int, r io.Reader, opts ...ParseOption) ([]*pocketsmith.Transaction, error) {
+	categories, err := client.ListCategories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	sections, err := Parse(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	index := CategoryIndex(categories)
+
+	var transactions []*pocketsmith.Transaction
+	for _, section := range sections {
+		transactions = append(transactions, section.Transactions(index)...)
+	}
+
+	return transactions, nil
+}
+
+func normaliseType(sectionType string) string {
+	switch strings.ToLower(sectionType) {
+	case "cash":
+		return TypeCash
+	case "ccard":
+		return TypeCCard
+	}
+	return TypeBank
+}
+
+// stripClass removes the "/Class" suffix QIF allows on categories.
+func stripClass(category string) string {
+	if i := strings.Index(category, "/"); i >= 0 && !strings.HasPrefix(category, "[") {
+		return category[:i]
+	}
+	return category
+}
+
+func parseAmount(value string) (float64, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// parseDate accepts the common QIF date forms, such as 1/31/2024, 01/31'24
+// and 1-31-24, and returns the date as YYYY-MM-DD.
+func parseDate(value string, dayFirst bool) (string, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\'' || r == ' '
+	})
+	if len(fields) != 3 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+
+	var nums [3]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q", value)
+		}
+		nums[i] = n
+	}
+
+	month, day, year := nums[0], nums[1], nums[2]
+	if dayFirst {
+		month, day = day, month
+	}
+	if len(fields[0]) == 4 {
+		// ISO style YYYY-MM-DD.
+		year, month, day = nums[0], nums[1], nums[2]
+	}
+
+	if year < 100 {
+		// Quicken writes years from 2000 onwards with an apostrophe, e.g. 1/2'05.
+		if strings.Contains(value, "'") || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day), nil
+}
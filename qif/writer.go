@@ -0,0 +1,142 @@
+package qif
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	categoryPaths map[int]string
+}
+
+// WithCategories writes sub-categories in QIF's "Parent:Child" form, using
+// the category tree returned by ListCategories. Without it only the
+// category's own title is written.
+func WithCategories(categories []*pocketsmith.Category) WriteOption {
+	return func(o *writeOptions) {
+		o.categoryPaths = make(map[int]string)
+
+		var walk func(prefix string, categories []*pocketsmith.Category)
+		walk = func(prefix string, categories []*pocketsmith.Category) {
+			for _, category := range categories {
+				path := category.Title
+				if prefix != "" {
+					path = prefix + ":" + category.Title
+				}
+				o.categoryPaths[category.ID] = path
+				walk(path, category.Children)
+			}
+		}
+		walk("", categories)
+	}
+}
+
+// Write writes transactions as QIF. Transactions are grouped by their
+// transaction account, with each group preceded by an "!Account" block so the
+// file can be imported into desktop software as multiple accounts. Credit card
+// accounts are written as CCard sections, everything else as Bank.
+func Write(w io.Writer, transactions []*pocketsmith.DetailedTransaction, opts ...WriteOption) error {
+	options := &writeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	type group struct {
+		account      *pocketsmith.TransactionAccount
+		transactions []*pocketsmith.DetailedTransaction
+	}
+
+	var groups []*group
+	byAccount := make(map[int]*group)
+	for _, tx := range transactions {
+		id := 0
+		if tx.TransactionAccount != nil {
+			id = tx.TransactionAccount.ID
+		}
+
+		g, ok := byAccount[id]
+		if !ok {
+			g = &group{account: tx.TransactionAccount}
+			byAccount[id] = g
+			groups = append(groups, g)
+		}
+		g.transactions = append(g.transactions, tx)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, g := range groups {
+		sectionType := TypeBank
+		if g.account != nil && g.account.Type == pocketsmith.AccountTypeCredits {
+			sectionType = TypeCCard
+		}
+
+		if g.account != nil {
+			fmt.Fprintf(bw, "!Account\nN%s\nT%s\n^\n", field(g.account.Name), sectionType)
+		}
+		fmt.Fprintf(bw, "!Type:%s\n", sectionType)
+
+		for _, tx := range g.transactions {
+			if err := writeTransaction(bw, tx, options); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeTransaction(w io.Writer, tx *pocketsmith.DetailedTransaction, options *writeOptions) error {
+	date, err := time.Parse("2006-01-02", tx.Date)
+	if err != nil {
+		return fmt.Errorf("transaction %d: invalid date %q", tx.ID, tx.Date)
+	}
+
+	fmt.Fprintf(w, "D%s\n", date.Format("01/02/2006"))
+	fmt.Fprintf(w, "T%s\n", strconv.FormatFloat(tx.Amount, 'f', 2, 64))
+	if !tx.NeedsReview {
+		fmt.Fprint(w, "C*\n")
+	}
+	if tx.ChequeNumber != "" {
+		fmt.Fprintf(w, "N%s\n", field(tx.ChequeNumber))
+	}
+	if tx.Payee != "" {
+		fmt.Fprintf(w, "P%s\n", field(tx.Payee))
+	}
+	if tx.Memo != "" {
+		fmt.Fprintf(w, "M%s\n", field(tx.Memo))
+	}
+	if tx.IsTransfer {
+		// PocketSmith doesn't record the other side of a transfer, so the
+		// transaction's own account is named. Read reports any "[Account]"
+		// category as a transfer.
+		account := "Transfer"
+		if tx.TransactionAccount != nil && tx.TransactionAccount.Name != "" {
+			account = tx.TransactionAccount.Name
+		}
+		fmt.Fprintf(w, "L[%s]\n", field(account))
+	} else if tx.Category != nil {
+		category := tx.Category.Title
+		if path, ok := options.categoryPaths[tx.Category.ID]; ok {
+			category = path
+		}
+		fmt.Fprintf(w, "L%s\n", field(category))
+	}
+	_, err = fmt.Fprint(w, "^\n")
+	return err
+}
+
+// lineBreaks replaces line breaks, which would end a QIF field early and
+// corrupt the record.
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+func field(value string) string {
+	return lineBreaks.Replace(value)
+}
@@ -0,0 +1,270 @@
+// Package reconcile checks a transaction account against a bank statement:
+// that the balances agree and, when the statement's entries are known, which
+// transactions are missing, extra or different.
+package reconcile
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/statement"
+)
+
+// DefaultDateWindow is used when Options.DateWindow is zero.
+const DefaultDateWindow = 3
+
+// Statement is the statement to reconcile against. StartDate and EndDate are
+// inclusive, YYYY-MM-DD. OpeningBalance is the balance before StartDate and
+// ClosingBalance the balance at the end of EndDate. Entries may be left empty
+// to only check balances.
+type Statement struct {
+	StartDate      string
+	EndDate        string
+	OpeningBalance float64
+	ClosingBalance float64
+	Entries        []*statement.Entry
+}
+
+// FromStatement returns the Statement for a parsed statement. The period runs
+// from the earliest entry, or the opening balance date when there are no
+// entries, to the closing balance date, or the latest entry when the
+// statement has no closing date.
+func FromStatement(s *statement.Statement) *Statement {
+	result := &Statement{
+		StartDate:      s.OpeningDate,
+		EndDate:        s.ClosingDate,
+		OpeningBalance: s.OpeningBalance,
+		ClosingBalance: s.ClosingBalance,
+		Entries:        s.Entries,
+	}
+
+	var first, last string
+	for _, entry := range s.Entries {
+		if first == "" || entry.BookingDate < first {
+			first = entry.BookingDate
+		}
+		if entry.BookingDate > last {
+			last = entry.BookingDate
+		}
+	}
+	if first != "" {
+		result.StartDate = first
+	}
+	if result.EndDate == "" {
+		result.EndDate = last
+	}
+
+	return result
+}
+
+// Options controls how entries are matched to transactions.
+type Options struct {
+	// DateWindow is how many days apart an entry's booking date and a
+	// transaction's date may be, as banks and feeds don't always agree.
+	DateWindow int
+}
+
+// Mismatch is an entry and a transaction with the same reference that
+// disagree on amount or date.
+type Mismatch struct {
+	Entry            *statement.Entry                 `json:"entry"`
+	Transaction      *pocketsmith.DetailedTransaction `json:"transaction"`
+	AmountDifference float64                          `json:"amount_difference"`
+	DaysApart        int                              `json:"days_apart"`
+}
+
+// Report is the result of a reconciliation.
+type Report struct {
+	StartDate      string  `json:"start_date"`
+	EndDate        string  `json:"end_date"`
+	OpeningBalance float64 `json:"opening_balance"`
+	ClosingBalance float64 `json:"closing_balance"`
+	// TransactionTotal is the sum of the account's transactions in the
+	// period, and ExpectedClosingBalance the opening balance plus that sum.
+	TransactionTotal       float64 `json:"transaction_total"`
+	ExpectedClosingBalance float64 `json:"expected_closing_balance"`
+	// Discrepancy is the statement's closing balance less the expected one:
+	// the net amount of transactions missing from PocketSmith.
+	Discrepancy float64 `json:"discrepancy"`
+	// PocketSmithClosingBalance is the closing balance of the account's
+	// last transaction in the period, and BalanceDiscrepancy the statement's
+	// closing balance less it. Both are zero when the period has no
+	// transactions.
+	PocketSmithClosingBalance float64 `json:"pocketsmith_closing_balance"`
+	BalanceDiscrepancy        float64 `json:"balance_discrepancy"`
+
+	Matched    int                                `json:"matched"`
+	Missing    []*statement.Entry                 `json:"missing,omitempty"`
+	Extra      []*pocketsmith.DetailedTransaction `json:"extra,omitempty"`
+	Mismatched []*Mismatch                        `json:"mismatched,omitempty"`
+}
+
+// Reconciled reports whether the balances agree and, if entries were
+// compared, every entry matched a transaction exactly.
+func (r *Report) Reconciled() bool {
+	return math.Abs(r.Discrepancy) < 0.005 && math.Abs(r.BalanceDiscrepancy) < 0.005 &&
+		len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+// Reconcile lists the transaction account's transactions in the statement's
+// period and compares them with it.
+func Reconcile(client *pocketsmith.Client, transactionAccountID int, stmt *Statement, opts Options) (*Report, error) {
+	var transactions []*pocketsmith.DetailedTransaction
+	for page := 1; ; page++ {
+		txs, err := client.ListTransactionsInTransactionAccount(transactionAccountID,
+			pocketsmith.WithStartDate(stmt.StartDate),
+			pocketsmith.WithEndDate(stmt.EndDate),
+			pocketsmith.WithPage(page),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error listing transactions: %w", err)
+		}
+		if len(txs) == 0 {
+			break
+		}
+		transactions = append(transactions, txs...)
+	}
+
+	return Compare(stmt, transactions, opts)
+}
+
+// Compare reconciles the statement against transactions already fetched for
+// its period. Transactions outside the period are ignored.
+func Compare(stmt *Statement, transactions []*pocketsmith.DetailedTransaction, opts Options) (*Report, error) {
+	window := opts.DateWindow
+	if window == 0 {
+		window = DefaultDateWindow
+	}
+
+	report := &Report{
+		StartDate:      stmt.StartDate,
+		EndDate:        stmt.EndDate,
+		OpeningBalance: stmt.OpeningBalance,
+		ClosingBalance: stmt.ClosingBalance,
+	}
+
+	var inPeriod []*pocketsmith.DetailedTransaction
+	var last *pocketsmith.DetailedTransaction
+	for _, tx := range transactions {
+		if tx.Date < stmt.StartDate || (stmt.EndDate != "" && tx.Date > stmt.EndDate) {
+			continue
+		}
+		inPeriod = append(inPeriod, tx)
+		report.TransactionTotal += tx.Amount
+		if last == nil || tx.Date > last.Date || (tx.Date == last.Date && tx.ID > last.ID) {
+			last = tx
+		}
+	}
+
+	report.TransactionTotal = round(report.TransactionTotal)
+	report.ExpectedClosingBalance = round(stmt.OpeningBalance + report.TransactionTotal)
+	report.Discrepancy = round(stmt.ClosingBalance - report.ExpectedClosingBalance)
+	if last != nil {
+		report.PocketSmithClosingBalance = last.ClosingBalance
+		report.BalanceDiscrepancy = round(stmt.ClosingBalance - last.ClosingBalance)
+	}
+
+	if len(stmt.Entries) == 0 {
+		return report, nil
+	}
+
+	if err := match(report, stmt.Entries, inPeriod, window); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// match pairs entries with transactions, first by reference, then by exact
+// amount on the same day, then by exact amount within the date window.
+// Entries and transactions left over are reported as missing and extra.
+func match(report *Report, entries []*statement.Entry, transactions []*pocketsmith.DetailedTransaction, window int) error {
+	dates := make(map[*pocketsmith.DetailedTransaction]time.Time, len(transactions))
+	for _, tx := range transactions {
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			return fmt.Errorf("transaction %d: invalid date %q", tx.ID, tx.Date)
+		}
+		dates[tx] = date
+	}
+
+	used := make(map[*pocketsmith.DetailedTransaction]bool)
+	matched := make(map[*statement.Entry]bool)
+
+	daysApart := func(entry *statement.Entry, tx *pocketsmith.DetailedTransaction) (int, error) {
+		date, err := time.Parse("2006-01-02", entry.BookingDate)
+		if err != nil {
+			return 0, fmt.Errorf("invalid booking date %q", entry.BookingDate)
+		}
+		return int(math.Abs(dates[tx].Sub(date).Hours()) / 24), nil
+	}
+
+	for _, entry := range entries {
+		if entry.Reference == "" {
+			continue
+		}
+		for _, tx := range transactions {
+			if used[tx] || !strings.Contains(tx.Memo, entry.Reference) {
+				continue
+			}
+			days, err := daysApart(entry, tx)
+			if err != nil {
+				return err
+			}
+			used[tx], matched[entry] = true, true
+			if difference := round(tx.Amount - entry.Amount); difference != 0 || days > window {
+				report.Mismatched = append(report.Mismatched, &Mismatch{Entry: entry, Transaction: tx, AmountDifference: difference, DaysApart: days})
+			} else {
+				report.Matched++
+			}
+			break
+		}
+	}
+
+	for _, maxDays := range []int{0, window} {
+		for _, entry := range entries {
+			if matched[entry] {
+				continue
+			}
+			for _, tx := range transactions {
+				if used[tx] || math.Abs(tx.Amount-entry.Amount) >= 0.005 {
+					continue
+				}
+				days, err := daysApart(entry, tx)
+				if err != nil {
+					return err
+				}
+				if days > maxDays {
+					continue
+				}
+				used[tx], matched[entry] = true, true
+				report.Matched++
+				break
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if !matched[entry] {
+			report.Missing = append(report.Missing, entry)
+		}
+	}
+	for _, tx := range transactions {
+		if !used[tx] {
+			report.Extra = append(report.Extra, tx)
+		}
+	}
+	sort.SliceStable(report.Extra, func(i, j int) bool {
+		return report.Extra[i].Date < report.Extra[j].Date
+	})
+
+	return nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,109 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+// Redacted replaces the values of redacted fields.
+const Redacted = "REDACTED"
+
+// DefaultRedactedFields are JSON keys and query parameters holding personal
+// data in API requests and responses.
+var DefaultRedactedFields = []string{
+	"email",
+	"login",
+	"name",
+	"avatar_url",
+	"number",
+	"data_feeds_account_id",
+	"data_feeds_connection_id",
+	"tell_a_friend_code",
+	"tell_a_friend_link",
+	"original_file_name",
+	"url",
+	"payee",
+	"original_payee",
+	"memo",
+	"note",
+	"cheque_number",
+	"title",
+	"search",
+}
+
+func (r *Recorder) scrub(interaction *Interaction) {
+	interaction.Request.Query = r.redactQuery(interaction.Request.Query)
+	interaction.Request.JSON = r.redact(interaction.Request.JSON)
+	interaction.Response.JSON = r.redact(interaction.Response.JSON)
+	for _, scrubber := range r.scrubbers {
+		scrubber(interaction)
+	}
+}
+
+// redactQuery replaces the values of redacted query parameters.
+func (r *Recorder) redactQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+
+	changed := false
+	for key, params := range values {
+		if !r.redacted[key] {
+			continue
+		}
+		for i, param := range params {
+			if param != "" {
+				params[i] = Redacted
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return query
+	}
+	return canonicalQuery(values)
+}
+
+// redact replaces the string values of redacted keys anywhere in the JSON
+// document. Numbers, booleans and nulls are left alone, as replacing them
+// with a string would break decoding.
+func (r *Recorder) redact(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return data
+	}
+
+	// UseNumber keeps IDs and amounts exactly as the API sent them.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+	value = r.redactValue(value)
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+func (r *Recorder) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if s, ok := field.(string); ok && r.redacted[key] && s != "" {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = r.redactValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+	return value
+}
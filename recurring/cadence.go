@@ -0,0 +1,137 @@
+package recurring
+
+import (
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Cadence is how often a recurring transaction happens.
+type Cadence string
+
+const (
+	Weekly      Cadence = "weekly"
+	Fortnightly Cadence = "fortnightly"
+	Monthly     Cadence = "monthly"
+	Quarterly   Cadence = "quarterly"
+	Yearly      Cadence = "yearly"
+)
+
+type cadenceRange struct {
+	cadence  Cadence
+	min, max int
+	grace    int
+}
+
+// cadences lists the accepted interval in days for each cadence, and how
+// many days late a payment may be before it is considered missed.
+var cadences = []cadenceRange{
+	{Weekly, 6, 8, 3},
+	{Fortnightly, 13, 16, 4},
+	{Monthly, 27, 33, 7},
+	{Quarterly, 85, 97, 10},
+	{Yearly, 355, 375, 14},
+}
+
+func lookupCadence(c Cadence) (cadenceRange, bool) {
+	for _, r := range cadences {
+		if r.cadence == c {
+			return r, true
+		}
+	}
+	return cadenceRange{}, false
+}
+
+// Next returns the date the next occurrence after date is expected.
+func (c Cadence) Next(date time.Time) time.Time {
+	switch c {
+	case Weekly:
+		return date.AddDate(0, 0, 7)
+	case Fortnightly:
+		return date.AddDate(0, 0, 14)
+	case Monthly:
+		return addMonths(date, 1)
+	case Quarterly:
+		return addMonths(date, 3)
+	case Yearly:
+		return addMonths(date, 12)
+	}
+	return time.Time{}
+}
+
+// RepeatType returns the budget event repeat type and interval matching the
+// cadence.
+func (c Cadence) RepeatType() (pocketsmith.EventRepeatType, int) {
+	switch c {
+	case Weekly:
+		return pocketsmith.EventRepeatWeekly, 1
+	case Fortnightly:
+		return pocketsmith.EventRepeatFortnightly, 1
+	case Monthly:
+		return pocketsmith.EventRepeatMonthly, 1
+	case Quarterly:
+		return pocketsmith.EventRepeatMonthly, 3
+	case Yearly:
+		return pocketsmith.EventRepeatYearly, 1
+	}
+	return pocketsmith.EventRepeatOnce, 1
+}
+
+// addMonths adds months to date, clamping to the end of shorter months
+// rather than overflowing into the next one.
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	day := date.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// inferCadence returns the cadence matching the gaps between dates, which
+// must be sorted. Gaps that are a small multiple of the cadence count as
+// missed payments rather than breaking the series, but at least three
+// quarters of the gaps must match the cadence exactly.
+func inferCadence(dates []time.Time) (Cadence, bool) {
+	if len(dates) < 2 {
+		return "", false
+	}
+
+	gaps := make([]int, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		gaps = append(gaps, days(dates[i].Sub(dates[i-1])))
+	}
+
+	for _, r := range cadences {
+		exact, missed := 0, 0
+		for _, gap := range gaps {
+			switch multiple(r, gap) {
+			case 1:
+				exact++
+			case 0:
+			default:
+				missed++
+			}
+		}
+		if exact+missed == len(gaps) && exact*4 >= len(gaps)*3 {
+			return r.cadence, true
+		}
+	}
+
+	return "", false
+}
+
+// multiple returns how many cadence periods gap spans, or 0 if it isn't
+// close to a whole number of them.
+func multiple(r cadenceRange, gap int) int {
+	for k := 1; k <= 3; k++ {
+		if gap >= r.min*k && gap <= r.max*k {
+			return k
+		}
+	}
+	return 0
+}
+
+func days(d time.Duration) int {
+	return int((d + 12*time.Hour) / (24 * time.Hour))
+}
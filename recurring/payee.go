@@ -0,0 +1,31 @@
+package recurring
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go/payees"
+)
+
+// defaultNormalizer is payees.DefaultStrip plus the "direct debit" prefix
+// some banks put on recurring payments, in lower case.
+var defaultNormalizer = &payees.Normalizer{
+	Strip: append(append([]*regexp.Regexp(nil), payees.DefaultStrip...),
+		regexp.MustCompile(`(?i)^\s*(direct debit|dd)\s+`)),
+	Case: payees.CaseLower,
+}
+
+// NormalizePayee reduces a payee to a key shared by every charge from the
+// same merchant, using the normaliser Detector uses by default: lower case,
+// without card processor noise, reference numbers and dates.
+// "NETFLIX.COM 866-579-7172" and "Netflix.com #1234" both become
+// "netflix.com".
+func NormalizePayee(payee string) string {
+	return payeeKey(defaultNormalizer, payee)
+}
+
+// payeeKey normalises payee with n, ignoring case so aliases and title case
+// don't split a series.
+func payeeKey(n *payees.Normalizer, payee string) string {
+	return strings.ToLower(n.Normalize(payee))
+}
@@ -0,0 +1,316 @@
+// Package recurring finds subscriptions and other recurring transactions in a
+// user's history, predicts when they next occur and flags price changes and
+// missed payments.
+package recurring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/payees"
+)
+
+const (
+	// DefaultAmountTolerance is used when Detector.AmountTolerance is zero.
+	DefaultAmountTolerance = 0.1
+	// DefaultMinOccurrences is used when Detector.MinOccurrences is zero.
+	DefaultMinOccurrences = 3
+)
+
+// PriceChange is a change in amount between two occurrences.
+type PriceChange struct {
+	Date string  `json:"date"`
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Series is a recurring transaction.
+type Series struct {
+	// Payee is the payee of the latest occurrence and Key the normalised
+	// payee the series was grouped by.
+	Payee              string                          `json:"payee"`
+	Key                string                          `json:"key"`
+	Cadence            Cadence                         `json:"cadence"`
+	Amount             float64                         `json:"amount"`
+	AverageAmount      float64                         `json:"average_amount"`
+	Category           *pocketsmith.Category           `json:"category"`
+	TransactionAccount *pocketsmith.TransactionAccount `json:"transaction_account"`
+	FirstDate          string                          `json:"first_date"`
+	LastDate           string                          `json:"last_date"`
+	NextDate           string                          `json:"next_date"`
+	PriceChanges       []PriceChange                   `json:"price_changes,omitempty"`
+	// MissedDates are dates an occurrence was expected but not found, both
+	// gaps in the history and, when Overdue, NextDate.
+	MissedDates []string `json:"missed_dates,omitempty"`
+	// Overdue is set when NextDate has passed by more than a few days.
+	Overdue bool `json:"overdue"`
+	// Active is unset when more than one occurrence in a row is overdue,
+	// which usually means the subscription was cancelled.
+	Active       bool                               `json:"active"`
+	Transactions []*pocketsmith.DetailedTransaction `json:"transactions"`
+}
+
+// Detector finds recurring transactions.
+type Detector struct {
+	// AmountTolerance is how far, as a fraction, the amounts of two
+	// occurrences may differ.
+	AmountTolerance float64
+	// MinOccurrences is how many transactions a series needs.
+	MinOccurrences int
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+	// Normalizer cleans payees before they are grouped, ignoring case. It
+	// defaults to the normaliser NormalizePayee uses.
+	Normalizer *payees.Normalizer
+}
+
+func NewDetector() *Detector {
+	return &Detector{AmountTolerance: DefaultAmountTolerance, MinOccurrences: DefaultMinOccurrences}
+}
+
+type occurrence struct {
+	tx   *pocketsmith.DetailedTransaction
+	date time.Time
+}
+
+type cluster struct {
+	occurrences []occurrence
+	cadence     Cadence
+}
+
+func (c *cluster) last() occurrence {
+	return c.occurrences[len(c.occurrences)-1]
+}
+
+// Detect returns the recurring series in transactions, most recent first.
+// Transfers are ignored. Transactions are grouped by normalised payee and
+// direction, then by amount; a series whose amount changes beyond
+// AmountTolerance is kept together when the new amount continues on
+// schedule.
+func (d *Detector) Detect(transactions []*pocketsmith.DetailedTransaction) []*Series {
+	tolerance := d.AmountTolerance
+	if tolerance == 0 {
+		tolerance = DefaultAmountTolerance
+	}
+	minOccurrences := d.MinOccurrences
+	if minOccurrences == 0 {
+		minOccurrences = DefaultMinOccurrences
+	}
+	now := time.Now
+	if d.Now != nil {
+		now = d.Now
+	}
+	normalizer := d.Normalizer
+	if normalizer == nil {
+		normalizer = defaultNormalizer
+	}
+
+	groups := make(map[string][]occurrence)
+	for _, tx := range transactions {
+		if tx.IsTransfer || tx.Amount == 0 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+		key := payeeKey(normalizer, tx.Payee)
+		if key == "" {
+			continue
+		}
+		if tx.Amount < 0 {
+			key += "\x00-"
+		}
+		groups[key] = append(groups[key], occurrence{tx, date})
+	}
+
+	var series []*Series
+	for key, occurrences := range groups {
+		sort.SliceStable(occurrences, func(i, j int) bool {
+			return occurrences[i].date.Before(occurrences[j].date)
+		})
+
+		var clusters []*cluster
+	next:
+		for _, o := range occurrences {
+			for _, c := range clusters {
+				if withinTolerance(c.last().tx.Amount, o.tx.Amount, tolerance) {
+					c.occurrences = append(c.occurrences, o)
+					continue next
+				}
+			}
+			clusters = append(clusters, &cluster{occurrences: []occurrence{o}})
+		}
+
+		for _, c := range mergePriceChanges(clusters, minOccurrences) {
+			if c.cadence == "" && len(c.occurrences) >= minOccurrences {
+				c.cadence, _ = inferCadence(dates(c.occurrences))
+			}
+			if c.cadence == "" {
+				continue
+			}
+			series = append(series, newSeries(c, strings.TrimSuffix(key, "\x00-"), now()))
+		}
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].LastDate != series[j].LastDate {
+			return series[i].LastDate > series[j].LastDate
+		}
+		return series[i].Key < series[j].Key
+	})
+
+	return series
+}
+
+// mergePriceChanges appends clusters that start on schedule right after an
+// earlier cluster ends to that cluster, so a price rise doesn't split a
+// subscription in two. Clusters are ordered by their first occurrence.
+func mergePriceChanges(clusters []*cluster, minOccurrences int) []*cluster {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].occurrences[0].date.Before(clusters[j].occurrences[0].date)
+	})
+
+	var merged []*cluster
+	for _, c := range clusters {
+		appended := false
+		for _, m := range merged {
+			if m.cadence == "" {
+				if len(m.occurrences) < minOccurrences {
+					continue
+				}
+				var ok bool
+				if m.cadence, ok = inferCadence(dates(m.occurrences)); !ok {
+					continue
+				}
+			}
+			r, _ := lookupCadence(m.cadence)
+			if !c.occurrences[0].date.After(m.last().date) {
+				continue
+			}
+			if multiple(r, days(c.occurrences[0].date.Sub(m.last().date))) == 0 {
+				continue
+			}
+			all := append(append([]occurrence(nil), m.occurrences...), c.occurrences...)
+			if cadence, ok := inferCadence(dates(all)); ok && cadence == m.cadence {
+				m.occurrences = all
+				appended = true
+				break
+			}
+		}
+		if !appended {
+			merged = append(merged, c)
+		}
+	}
+
+	return merged
+}
+
+func newSeries(c *cluster, key string, now time.Time) *Series {
+	first, last := c.occurrences[0], c.last()
+	s := &Series{
+		Payee:              last.tx.Payee,
+		Key:                key,
+		Cadence:            c.cadence,
+		Amount:             last.tx.Amount,
+		Category:           last.tx.Category,
+		TransactionAccount: last.tx.TransactionAccount,
+		FirstDate:          first.tx.Date,
+		LastDate:           last.tx.Date,
+		Active:             true,
+	}
+
+	r, _ := lookupCadence(c.cadence)
+	var total float64
+	for i, o := range c.occurrences {
+		s.Transactions = append(s.Transactions, o.tx)
+		total += o.tx.Amount
+		if i == 0 {
+			continue
+		}
+
+		prev := c.occurrences[i-1]
+		if math.Abs(o.tx.Amount-prev.tx.Amount) >= 0.005 {
+			s.PriceChanges = append(s.PriceChanges, PriceChange{Date: o.tx.Date, From: prev.tx.Amount, To: o.tx.Amount})
+		}
+
+		expected := prev.date
+		for k := multiple(r, days(o.date.Sub(prev.date))); k > 1; k-- {
+			expected = c.cadence.Next(expected)
+			s.MissedDates = append(s.MissedDates, expected.Format("2006-01-02"))
+		}
+	}
+	s.AverageAmount = math.Round(total/float64(len(c.occurrences))*100) / 100
+
+	next := c.cadence.Next(last.date)
+	s.NextDate = next.Format("2006-01-02")
+	if now.After(next.AddDate(0, 0, r.grace)) {
+		s.Overdue = true
+		s.MissedDates = append(s.MissedDates, s.NextDate)
+		if now.After(c.cadence.Next(next).AddDate(0, 0, r.grace)) {
+			s.Active = false
+		}
+	}
+
+	return s
+}
+
+func withinTolerance(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= math.Max(math.Abs(a)*tolerance, 0.01)
+}
+
+func dates(occurrences []occurrence) []time.Time {
+	result := make([]time.Time, len(occurrences))
+	for i, o := range occurrences {
+		result[i] = o.date
+	}
+	return result
+}
+
+// Detect lists the user's transactions and returns the recurring series found
+// with the default settings. opts are passed to ListTransactionsInUser, for
+// example to limit the history analysed.
+func Detect(client *pocketsmith.Client, userID int, opts ...pocketsmith.ListTransactionsOption) ([]*Series, error) {
+	var transactions []*pocketsmith.DetailedTransaction
+	err := client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		transactions = append(transactions, page...)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return NewDetector().Detect(transactions), nil
+}
+
+// CreateEvents creates a repeating budget event in the scenario for each
+// active series, starting at its next date. Series without a category are
+// skipped, as events require one.
+func CreateEvents(client *pocketsmith.Client, scenarioID int, series []*Series) ([]*pocketsmith.Event, error) {
+	var events []*pocketsmith.Event
+	for _, s := range series {
+		if !s.Active || s.Category == nil {
+			continue
+		}
+
+		repeatType, interval := s.Cadence.RepeatType()
+		event, err := client.CreateEvent(scenarioID, &pocketsmith.CreateEvent{
+			CategoryID:     s.Category.ID,
+			Amount:         s.Amount,
+			Date:           s.NextDate,
+			RepeatType:     repeatType,
+			RepeatInterval: interval,
+			Note:           s.Payee,
+		})
+		if err != nil {
+			return events, fmt.Errorf("error creating event for %s: %w", s.Payee, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
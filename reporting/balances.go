@@ -0,0 +1,212 @@
+// Package reporting reconstructs historical account balances and net worth
+// from transaction history.
+package reporting
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type Interval string
+
+const (
+	Daily   Interval = "daily"
+	Monthly Interval = "monthly"
+)
+
+const dateLayout = "2006-01-02"
+
+// Point is a balance at the end of a day.
+type Point struct {
+	Date                  string  `json:"date"`
+	Balance               float64 `json:"balance"`
+	BalanceInBaseCurrency float64 `json:"balance_in_base_currency"`
+}
+
+// Series is the balance history of a transaction account.
+type Series struct {
+	TransactionAccount *pocketsmith.TransactionAccount `json:"transaction_account"`
+	Points             []Point                         `json:"points"`
+}
+
+type SeriesOption func(*seriesOptions)
+
+type seriesOptions struct {
+	useClosingBalance bool
+}
+
+// WithClosingBalances takes balances from the ClosingBalance of the last
+// transaction of each day instead of adding up transaction amounts.
+func WithClosingBalances() SeriesOption {
+	return func(o *seriesOptions) {
+		o.useClosingBalance = true
+	}
+}
+
+// Dates returns the dates a series is sampled on: every day from start to
+// end, or the last day of every month with end as the final point.
+func Dates(start, end time.Time, interval Interval) []string {
+	var dates []string
+	switch interval {
+	case Monthly:
+		for d := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !d.After(end); d = d.AddDate(0, 1, 0) {
+			last := d.AddDate(0, 1, -1)
+			if last.After(end) {
+				last = end
+			}
+			dates = append(dates, last.Format(dateLayout))
+		}
+	default:
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d.Format(dateLayout))
+		}
+	}
+	return dates
+}
+
+// BalanceSeries reconstructs the end-of-day balance of a transaction account
+// on each date from its transactions.
+//
+// When the account has a starting balance date, balances are worked forward
+// from the starting balance; transactions on the starting balance date are
+// taken to be included in it. Otherwise they are worked backward from the
+// current balance. With WithClosingBalances, the closing balance reported on
+// the last transaction of each day is used instead.
+//
+// Balances are converted to the base currency with the exchange rate implied
+// by the most recent transaction's AmountInBaseCurrency, falling back to the
+// account's current exchange rate.
+func BalanceSeries(ta *pocketsmith.TransactionAccount, transactions []*pocketsmith.DetailedTransaction, dates []string, opts ...SeriesOption) *Series {
+	options := &seriesOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	sorted := make([]*pocketsmith.DetailedTransaction, len(transactions))
+	copy(sorted, transactions)
+	// Break ties by ID so the last transaction of a day, whose closing balance
+	// is used, doesn't depend on the order the transactions were listed in.
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date < sorted[j].Date
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	balanceAt := balanceFunc(ta, sorted, options)
+	rateAt := rateFunc(ta, sorted)
+
+	series := &Series{TransactionAccount: ta, Points: make([]Point, 0, len(dates))}
+	for _, date := range dates {
+		balance := balanceAt(date)
+		series.Points = append(series.Points, Point{
+			Date:                  date,
+			Balance:               balance,
+			BalanceInBaseCurrency: balance * rateAt(date),
+		})
+	}
+
+	return series
+}
+
+// balanceFunc returns a function giving the balance at the end of a date.
+// Transactions must be sorted by date. Balances are worked out for every day
+// with transactions up front, so each lookup is a binary search.
+func balanceFunc(ta *pocketsmith.TransactionAccount, transactions []*pocketsmith.DetailedTransaction, options *seriesOptions) func(date string) float64 {
+	switch {
+	case options.useClosingBalance:
+		return forward(transactions, ta.StartingBalance, func(_ float64, tx *pocketsmith.DetailedTransaction) float64 {
+			return tx.ClosingBalance
+		})
+	case ta.StartingBalanceDate != "":
+		return forward(transactions, ta.StartingBalance, func(balance float64, tx *pocketsmith.DetailedTransaction) float64 {
+			if tx.Date > ta.StartingBalanceDate {
+				balance += tx.Amount
+			}
+			return balance
+		})
+	}
+
+	// Work backward from the current balance, which is the balance after the
+	// last transaction.
+	var days days
+	balance := ta.CurrentBalance
+	for i := len(transactions) - 1; i >= 0; i-- {
+		if i == len(transactions)-1 || transactions[i].Date != transactions[i+1].Date {
+			days.dates = append(days.dates, transactions[i].Date)
+			days.values = append(days.values, balance)
+		}
+		balance -= transactions[i].Amount
+	}
+	slices.Reverse(days.dates)
+	slices.Reverse(days.values)
+	return days.lookup(balance)
+}
+
+// rateFunc returns a function giving the base currency exchange rate on a
+// date. Transactions must be sorted by date.
+func rateFunc(ta *pocketsmith.TransactionAccount, transactions []*pocketsmith.DetailedTransaction) func(date string) float64 {
+	current := 1.0
+	switch {
+	case ta.CurrentBalance != 0 && ta.CurrentBalanceInBaseCurrency != 0:
+		current = ta.CurrentBalanceInBaseCurrency / ta.CurrentBalance
+	case ta.CurrentBalanceExchangeRate != 0:
+		current = ta.CurrentBalanceExchangeRate
+	}
+
+	rateAt := forward(transactions, 0, func(rate float64, tx *pocketsmith.DetailedTransaction) float64 {
+		if tx.Amount != 0 && tx.AmountInBaseCurrency != 0 {
+			rate = tx.AmountInBaseCurrency / tx.Amount
+		}
+		return rate
+	})
+	return func(date string) float64 {
+		if rate := rateAt(date); rate != 0 {
+			return rate
+		}
+		return current
+	}
+}
+
+// days holds a value at the end of each day with transactions, in date
+// order.
+type days struct {
+	dates  []string
+	values []float64
+}
+
+// lookup returns a function giving the value at the end of a date: that of
+// the latest day on or before it, or initial before the first day.
+func (d *days) lookup(initial float64) func(date string) float64 {
+	return func(date string) float64 {
+		i := sort.SearchStrings(d.dates, date)
+		if i < len(d.dates) && d.dates[i] == date {
+			return d.values[i]
+		}
+		if i == 0 {
+			return initial
+		}
+		return d.values[i-1]
+	}
+}
+
+// forward folds step over the transactions, which must be sorted by date,
+// starting from initial, and returns a lookup of the value at the end of
+// each day.
+func forward(transactions []*pocketsmith.DetailedTransaction, initial float64, step func(value float64, tx *pocketsmith.DetailedTransaction) float64) func(date string) float64 {
+	var days days
+	value := initial
+	for _, tx := range transactions {
+		value = step(value, tx)
+		if n := len(days.dates); n > 0 && days.dates[n-1] == tx.Date {
+			days.values[n-1] = value
+			continue
+		}
+		days.dates = append(days.dates, tx.Date)
+		days.values = append(days.values, value)
+	}
+	return days.lookup(initial)
+}
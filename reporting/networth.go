@@ -0,0 +1,112 @@
+package reporting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// NetWorthPoint is the net worth on a date, in the base currency. Liabilities
+// is the amount owed, as a positive number.
+type NetWorthPoint struct {
+	Date        string                              `json:"date"`
+	Assets      float64                             `json:"assets"`
+	Liabilities float64                             `json:"liabilities"`
+	NetWorth    float64                             `json:"net_worth"`
+	ByType      map[pocketsmith.AccountType]float64 `json:"by_type"`
+}
+
+// Report is the balance history of a user's accounts.
+type Report struct {
+	Series   []*Series       `json:"series"`
+	NetWorth []NetWorthPoint `json:"net_worth"`
+}
+
+// IsLiability reports whether balances of the account type are owed rather
+// than owned.
+func IsLiability(accountType pocketsmith.AccountType) bool {
+	switch accountType {
+	case pocketsmith.AccountTypeCredits, pocketsmith.AccountTypeLoans,
+		pocketsmith.AccountTypeMortgage, pocketsmith.AccountTypeOtherLiability:
+		return true
+	}
+	return false
+}
+
+// IncludedInNetWorth reports whether a transaction account counts towards net
+// worth.
+func IncludedInNetWorth(ta *pocketsmith.TransactionAccount) bool {
+	return ta.IncludeInNetWorth || ta.IsNetWorth
+}
+
+// NetWorth adds up the series of accounts included in net worth on each date.
+// All series must be sampled on the same dates.
+func NetWorth(series []*Series) []NetWorthPoint {
+	if len(series) == 0 {
+		return nil
+	}
+
+	points := make([]NetWorthPoint, len(series[0].Points))
+	for i, p := range series[0].Points {
+		points[i] = NetWorthPoint{Date: p.Date, ByType: make(map[pocketsmith.AccountType]float64)}
+	}
+
+	for _, s := range series {
+		if !IncludedInNetWorth(s.TransactionAccount) {
+			continue
+		}
+
+		for i, p := range s.Points {
+			if i >= len(points) {
+				break
+			}
+
+			balance := p.BalanceInBaseCurrency
+			points[i].ByType[s.TransactionAccount.Type] += balance
+			if IsLiability(s.TransactionAccount.Type) {
+				points[i].Liabilities -= balance
+			} else {
+				points[i].Assets += balance
+			}
+		}
+	}
+
+	for i := range points {
+		points[i].NetWorth = points[i].Assets - points[i].Liabilities
+	}
+
+	return points
+}
+
+// Build fetches the user's transaction accounts and transactions and
+// reconstructs balances and net worth between start and end.
+func Build(client *pocketsmith.Client, userID int, start, end time.Time, interval Interval, opts ...SeriesOption) (*Report, error) {
+	transactionAccounts, err := client.ListTransactionAccounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transaction accounts: %w", err)
+	}
+
+	byAccount := make(map[int][]*pocketsmith.DetailedTransaction)
+	err = client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		for _, tx := range page {
+			if tx.TransactionAccount != nil {
+				byAccount[tx.TransactionAccount.ID] = append(byAccount[tx.TransactionAccount.ID], tx)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	dates := Dates(start, end, interval)
+
+	report := &Report{}
+	for _, ta := range transactionAccounts {
+		report.Series = append(report.Series, BalanceSeries(ta, byAccount[ta.ID], dates, opts...))
+	}
+	report.NetWorth = NetWorth(report.Series)
+
+	return report, nil
+}
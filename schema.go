@@ -0,0 +1,262 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SchemaIssue is a difference between an API response and the struct it was
+// decoded into.
+type SchemaIssue struct {
+	// Endpoint is the request method and route, such as
+	// "GET /v2/users/{id}/accounts".
+	Endpoint string
+	// Path locates the field in the response, such as
+	// "[0].transaction_accounts[1].number".
+	Path string
+	// Unknown is set when the struct has no field for the key. Otherwise the
+	// field's type doesn't match the JSON value: Expected is the Go type and
+	// Got the JSON type.
+	Unknown  bool
+	Expected string
+	Got      string
+}
+
+func (i SchemaIssue) String() string {
+	if i.Unknown {
+		return fmt.Sprintf("%s: unknown field %s", i.Endpoint, i.Path)
+	}
+	return fmt.Sprintf("%s: %s is %s, expected %s", i.Endpoint, i.Path, i.Got, i.Expected)
+}
+
+// WithStrictDecoding compares each response with the struct it is decoded
+// into and calls report for every unknown field and every field whose JSON
+// type doesn't match, so API changes are noticed. Type mismatches no longer
+// fail the call; the mismatched fields are left zero.
+func WithStrictDecoding(report func(SchemaIssue)) ClientOption {
+	return func(c *Client) {
+		c.reportSchema = report
+	}
+}
+
+// WithExtraFields keeps the fields of each response that a model doesn't
+// know about in the model's Extra map, keyed by their JSON name.
+func WithExtraFields() ClientOption {
+	return func(c *Client) {
+		c.extraFields = true
+	}
+}
+
+// checkSchema walks the response alongside the value it was decoded into,
+// reporting issues and filling Extra maps as configured.
+func (c *Client) checkSchema(req *http.Request, body []byte, v any) {
+	w := &schemaWalker{endpoint: spanName(req), report: c.reportSchema, keepExtra: c.extraFields}
+	w.walk("", body, reflect.ValueOf(v))
+}
+
+type schemaWalker struct {
+	endpoint  string
+	report    func(SchemaIssue)
+	keepExtra bool
+}
+
+var (
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	extraType       = reflect.TypeOf(map[string]json.RawMessage{})
+)
+
+func (w *schemaWalker) mismatch(path string, t reflect.Type, got string) {
+	if w.report != nil {
+		w.report(SchemaIssue{Endpoint: w.endpoint, Path: path, Expected: t.String(), Got: got})
+	}
+}
+
+// walk checks data against v. v may be unaddressable, in which case Extra
+// maps below it aren't filled.
+func (w *schemaWalker) walk(path string, data []byte, v reflect.Value) {
+	kind := jsonKind(data)
+	if kind == "null" {
+		return
+	}
+
+	t := v.Type()
+	if t == rawMessageType || t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			w.walk(path, data, reflect.New(t.Elem()).Elem())
+			return
+		}
+		w.walk(path, data, v.Elem())
+	case reflect.Struct:
+		if kind != "object" {
+			w.mismatch(path, t, kind)
+			return
+		}
+		w.walkStruct(path, data, v)
+	case reflect.Slice, reflect.Array:
+		if kind != "array" {
+			w.mismatch(path, t, kind)
+			return
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return
+		}
+		for i, item := range items {
+			elem := reflect.New(t.Elem()).Elem()
+			if i < v.Len() {
+				elem = v.Index(i)
+			}
+			w.walk(fmt.Sprintf("%s[%d]", path, i), item, elem)
+		}
+	case reflect.Map:
+		if kind != "object" {
+			w.mismatch(path, t, kind)
+			return
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return
+		}
+		for key, field := range fields {
+			elem := reflect.New(t.Elem()).Elem()
+			if !v.IsNil() {
+				if value := v.MapIndex(reflect.ValueOf(key).Convert(t.Key())); value.IsValid() {
+					elem = value
+				}
+			}
+			w.walk(joinPath(path, key), field, elem)
+		}
+	case reflect.String:
+		if kind != "string" {
+			w.mismatch(path, t, kind)
+		}
+	case reflect.Bool:
+		if kind != "boolean" {
+			w.mismatch(path, t, kind)
+		}
+	case reflect.Float32, reflect.Float64:
+		if kind != "number" {
+			w.mismatch(path, t, kind)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if kind != "number" {
+			w.mismatch(path, t, kind)
+		} else if bytes.ContainsAny(data, ".eE") {
+			w.mismatch(path, t, "non-integer number")
+		}
+	}
+}
+
+func (w *schemaWalker) walkStruct(path string, data []byte, v reflect.Value) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+
+	info := structFields(v.Type())
+	var extra map[string]json.RawMessage
+	for key, field := range fields {
+		index, ok := info.fields[key]
+		if !ok {
+			index, ok = info.fields[strings.ToLower(key)]
+		}
+		if ok {
+			w.walk(joinPath(path, key), field, v.FieldByIndex(index))
+			continue
+		}
+
+		if w.report != nil {
+			w.report(SchemaIssue{Endpoint: w.endpoint, Path: joinPath(path, key), Unknown: true})
+		}
+		if w.keepExtra && info.extra != nil && v.CanSet() {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[key] = field
+		}
+	}
+
+	if extra != nil {
+		v.FieldByIndex(info.extra).Set(reflect.ValueOf(extra))
+	}
+}
+
+type structInfo struct {
+	// fields maps JSON names, and their lower case forms for the
+	// case-insensitive matching encoding/json does, to field indexes.
+	fields map[string][]int
+	// extra is the index of the Extra field, if the struct has one.
+	extra []int
+}
+
+var structInfoCache sync.Map
+
+func structFields(t reflect.Type) *structInfo {
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{fields: make(map[string][]int)}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			if field.Name == "Extra" && field.Type == extraType {
+				info.extra = field.Index
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		info.fields[name] = field.Index
+		if _, ok := info.fields[strings.ToLower(name)]; !ok {
+			info.fields[strings.ToLower(name)] = field.Index
+		}
+	}
+
+	structInfoCache.Store(t, info)
+	return info
+}
+
+func jsonKind(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return "null"
+	}
+	switch data[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,238 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID      string        `xml:"Id"`
+	Account camtAccount   `xml:"Acct"`
+	Balance []camtBalance `xml:"Bal"`
+	Entries []camtEntry   `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+type camtBalance struct {
+	Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        camtDate   `xml:"Dt"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+type camtTransactionDetails struct {
+	Amount         camtAmount `xml:"Amt"`
+	TxAmount       camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	CreditDebit    string     `xml:"CdtDbtInd"`
+	EndToEndID     string     `xml:"Refs>EndToEndId"`
+	ServicerRef    string     `xml:"Refs>AcctSvcrRef"`
+	Creditor       camtParty  `xml:"RltdPties>Cdtr"`
+	Debtor         camtParty  `xml:"RltdPties>Dbtr"`
+	Unstructured   []string   `xml:"RmtInf>Ustrd"`
+	CreditorRef    string     `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInfo string     `xml:"AddtlTxInf"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount               `xml:"Amt"`
+	CreditDebit    string                   `xml:"CdtDbtInd"`
+	BookingDate    camtDate                 `xml:"BookgDt"`
+	ValueDate      camtDate                 `xml:"ValDt"`
+	EntryRef       string                   `xml:"NtryRef"`
+	ServicerRef    string                   `xml:"AcctSvcrRef"`
+	Details        []camtTransactionDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string                   `xml:"AddtlNtryInf"`
+}
+
+// ParseCAMT053 parses a camt.053 bank-to-customer statement. A file can hold
+// several statements, one per account. Batch entries with more than one
+// transaction detail are expanded into one entry per transaction.
+func ParseCAMT053(r io.Reader) ([]*Statement, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error decoding camt.053: %w", err)
+	}
+
+	statements := make([]*Statement, 0, len(doc.Statements))
+	for _, stmt := range doc.Statements {
+		statement := &Statement{
+			ID:       stmt.ID,
+			Account:  stmt.Account.IBAN,
+			Currency: stmt.Account.Currency,
+		}
+		if statement.Account == "" {
+			statement.Account = stmt.Account.Other
+		}
+
+		for _, bal := range stmt.Balance {
+			amount, err := camtSignedAmount(bal.Amount, bal.CreditDebit)
+			if err != nil {
+				return nil, err
+			}
+
+			switch bal.Code {
+			case "OPBD", "PRCD":
+				statement.OpeningBalance = amount
+				statement.OpeningDate = camtDay(bal.Date)
+			case "CLBD":
+				statement.ClosingBalance = amount
+				statement.ClosingDate = camtDay(bal.Date)
+			}
+			if statement.Currency == "" {
+				statement.Currency = bal.Amount.Currency
+			}
+		}
+
+		for _, ntry := range stmt.Entries {
+			entries, err := camtEntries(ntry)
+			if err != nil {
+				return nil, err
+			}
+			statement.Entries = append(statement.Entries, entries...)
+		}
+
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
+
+func camtEntries(ntry camtEntry) ([]*Entry, error) {
+	bookingDate := camtDay(ntry.BookingDate)
+	valueDate := camtDay(ntry.ValueDate)
+	if bookingDate == "" {
+		bookingDate = valueDate
+	}
+
+	if len(ntry.Details) <= 1 {
+		amount, err := camtSignedAmount(ntry.Amount, ntry.CreditDebit)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &Entry{
+			BookingDate: bookingDate,
+			ValueDate:   valueDate,
+			Amount:      amount,
+			Currency:    ntry.Amount.Currency,
+			Memo:        cleanText(ntry.AdditionalInfo),
+			Reference:   firstNonEmpty(ntry.ServicerRef, ntry.EntryRef),
+		}
+		if len(ntry.Details) == 1 {
+			applyCamtDetails(entry, ntry.Details[0], ntry.CreditDebit)
+		}
+
+		return []*Entry{entry}, nil
+	}
+
+	entries := make([]*Entry, 0, len(ntry.Details))
+	for _, details := range ntry.Details {
+		amt := details.Amount
+		if amt.Value == "" {
+			amt = details.TxAmount
+		}
+		creditDebit := firstNonEmpty(details.CreditDebit, ntry.CreditDebit)
+
+		amount, err := camtSignedAmount(amt, creditDebit)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &Entry{
+			BookingDate: bookingDate,
+			ValueDate:   valueDate,
+			Amount:      amount,
+			Currency:    firstNonEmpty(amt.Currency, ntry.Amount.Currency),
+			Reference:   firstNonEmpty(details.ServicerRef, ntry.ServicerRef, ntry.EntryRef),
+		}
+		applyCamtDetails(entry, details, creditDebit)
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func applyCamtDetails(entry *Entry, details camtTransactionDetails, creditDebit string) {
+	counterparty := details.Debtor
+	if creditDebit == "DBIT" {
+		counterparty = details.Creditor
+	}
+	entry.Payee = cleanText(firstNonEmpty(counterparty.Name, counterparty.PartyName))
+
+	if memo := cleanText(details.Unstructured...); memo != "" {
+		entry.Memo = memo
+	} else if details.CreditorRef != "" {
+		entry.Memo = details.CreditorRef
+	} else if memo := cleanText(details.AdditionalInfo); memo != "" && entry.Memo == "" {
+		entry.Memo = memo
+	}
+
+	if details.EndToEndID != "" && details.EndToEndID != "NOTPROVIDED" {
+		entry.Reference = details.EndToEndID
+	} else if details.ServicerRef != "" {
+		entry.Reference = details.ServicerRef
+	}
+}
+
+// camtSignedAmount returns the amount, negative for debits. Reversals need no
+// special handling: their CdtDbtInd already gives the direction of the
+// reversal itself, so a reversed debit is a CRDT.
+func camtSignedAmount(amount camtAmount, creditDebit string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount.Value)
+	}
+
+	if creditDebit == "DBIT" {
+		value = -value
+	}
+
+	return value, nil
+}
+
+func camtDay(date camtDate) string {
+	if date.Date != "" {
+		return date.Date
+	}
+	if len(date.DateTime) >= 10 {
+		return date.DateTime[:10]
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
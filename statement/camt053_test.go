@@ -0,0 +1,53 @@
+package statement
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseCAMT053Amounts(t *testing.T) {
+	f, err := os.Open("testdata/camt053.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	statements, err := ParseCAMT053(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	stmt := statements[0]
+
+	if stmt.OpeningBalance != 100 || stmt.ClosingBalance != 1325 {
+		t.Errorf("balances = %v, %v, want 100, 1325", stmt.OpeningBalance, stmt.ClosingBalance)
+	}
+
+	want := []struct {
+		date   string
+		amount float64
+		payee  string
+	}{
+		{"2026-03-02", -25, "Corner Coffee"},
+		{"2026-03-15", 1200, "Employer GmbH"},
+		// A reversed debit is reported as a credit and refunds the account.
+		{"2026-03-20", 50, "Online Shop"},
+	}
+	if len(stmt.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(stmt.Entries), len(want))
+	}
+
+	total := stmt.OpeningBalance
+	for i, w := range want {
+		entry := stmt.Entries[i]
+		if entry.BookingDate != w.date || entry.Amount != w.amount || entry.Payee != w.payee {
+			t.Errorf("entry %d = %s %v %q, want %s %v %q", i, entry.BookingDate, entry.Amount, entry.Payee, w.date, w.amount, w.payee)
+		}
+		total += entry.Amount
+	}
+	if total != stmt.ClosingBalance {
+		t.Errorf("opening balance plus entries = %v, want closing balance %v", total, stmt.ClosingBalance)
+	}
+}
@@ -0,0 +1,71 @@
+package statement
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// ImportResult reports what Import did with each entry.
+type ImportResult struct {
+	Imported   []*pocketsmith.Transaction
+	Duplicates []*Entry
+}
+
+// Import adds the entries to a transaction account with AddTransaction.
+// Entries that already exist are skipped: an entry is a duplicate if a
+// transaction within a day of its booking date has the same amount and
+// contains its reference in the memo, or, for entries without a reference,
+// has the same memo.
+func Import(client *pocketsmith.Client, transactionAccountID int, entries []*Entry) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for _, entry := range entries {
+		duplicate, err := IsDuplicate(client, transactionAccountID, entry)
+		if err != nil {
+			return result, err
+		}
+		if duplicate {
+			result.Duplicates = append(result.Duplicates, entry)
+			continue
+		}
+
+		created, err := client.AddTransaction(transactionAccountID, entry.Transaction())
+		if err != nil {
+			return result, fmt.Errorf("error adding transaction %s %s: %w", entry.BookingDate, entry.Reference, err)
+		}
+		result.Imported = append(result.Imported, created)
+	}
+
+	return result, nil
+}
+
+// IsDuplicate reports whether the entry already exists in the transaction
+// account. See Import for the matching rules.
+func IsDuplicate(client *pocketsmith.Client, transactionAccountID int, entry *Entry) (bool, error) {
+	date, err := time.Parse("2006-01-02", entry.BookingDate)
+	if err != nil {
+		return false, fmt.Errorf("invalid booking date %q", entry.BookingDate)
+	}
+
+	var existing []*pocketsmith.DetailedTransaction
+	if entry.Reference != "" {
+		existing, err = client.SearchTransactionsByMemoContains(transactionAccountID, date, entry.Reference)
+	} else {
+		existing, err = client.SearchTransactionsByMemo(transactionAccountID, date, entry.memo())
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, tx := range existing {
+		if math.Abs(tx.Amount-entry.Amount) < 0.005 && (entry.Reference == "" || strings.Contains(tx.Memo, entry.Reference)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,293 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statementLine matches the :61: field: value date, optional entry date,
+// debit/credit mark, optional funds code, amount, transaction type and
+// references.
+var statementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?([0-9]+,[0-9]*)([A-Z][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// ParseMT940 parses a SWIFT MT940 customer statement. A file can hold several
+// messages; each one becomes a Statement.
+//
+// The :86: information field is understood in the common German "?nn"
+// sub-field layout and the SEPA "/EREF/.../REMI/..." layout. Anything else is
+// used verbatim as the memo.
+func ParseMT940(r io.Reader) ([]*Statement, error) {
+	fields, err := mt940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		statements []*Statement
+		statement  *Statement
+		entry      *Entry
+	)
+
+	for _, field := range fields {
+		switch field.tag {
+		case "20":
+			statement = &Statement{ID: field.value}
+			statements = append(statements, statement)
+			entry = nil
+		case "25":
+			if statement != nil {
+				statement.Account = field.value
+			}
+		case "60F", "60M":
+			if statement != nil {
+				currency, date, amount, err := mt940Balance(field.value)
+				if err != nil {
+					return nil, err
+				}
+				statement.Currency = currency
+				statement.OpeningBalance = amount
+				statement.OpeningDate = date
+			}
+		case "62F", "62M":
+			if statement != nil {
+				_, date, amount, err := mt940Balance(field.value)
+				if err != nil {
+					return nil, err
+				}
+				statement.ClosingBalance = amount
+				statement.ClosingDate = date
+			}
+		case "61":
+			if statement == nil {
+				return nil, fmt.Errorf("statement line before :20: field")
+			}
+			entry, err = mt940Entry(field.value)
+			if err != nil {
+				return nil, err
+			}
+			entry.Currency = statement.Currency
+			statement.Entries = append(statement.Entries, entry)
+		case "86":
+			if entry != nil {
+				applyMT940Information(entry, field.value)
+				entry = nil
+			}
+		}
+	}
+
+	return statements, nil
+}
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// mt940Fields splits the message into tagged fields, joining continuation
+// lines. Block headers such as {1:...}{4: and the trailing "-}" are ignored.
+func mt940Fields(r io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || line == "-" || line == "-}" || strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				fields = append(fields, mt940Field{tag: line[1 : end+1], value: line[end+2:]})
+				continue
+			}
+		}
+
+		if n := len(fields); n > 0 {
+			fields[n-1].value += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func mt940Entry(value string) (*Entry, error) {
+	line, supplementary, _ := strings.Cut(value, "\n")
+
+	m := statementLine.FindStringSubmatch(line)
+	if m == nil {
+		return nil, fmt.Errorf("invalid statement line %q", line)
+	}
+
+	valueDate, err := time.Parse("060102", m[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value date %q", m[1])
+	}
+
+	bookingDate := valueDate
+	if m[2] != "" {
+		entryDate, err := time.Parse("0102", m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry date %q", m[2])
+		}
+		bookingDate = time.Date(valueDate.Year(), entryDate.Month(), entryDate.Day(), 0, 0, 0, 0, time.UTC)
+		// The entry date has no year; it may fall either side of a year boundary.
+		if bookingDate.Sub(valueDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(-1, 0, 0)
+		} else if valueDate.Sub(bookingDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(1, 0, 0)
+		}
+	}
+
+	amount, err := mt940Amount(m[5])
+	if err != nil {
+		return nil, err
+	}
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	entry := &Entry{
+		BookingDate: bookingDate.Format("2006-01-02"),
+		ValueDate:   valueDate.Format("2006-01-02"),
+		Amount:      amount,
+		Memo:        cleanText(supplementary),
+	}
+
+	if ref := strings.TrimSpace(m[7]); ref != "" && ref != "NONREF" {
+		entry.Reference = ref
+	} else if ref := strings.TrimSpace(m[8]); ref != "" {
+		entry.Reference = ref
+	}
+
+	return entry, nil
+}
+
+func mt940Balance(value string) (string, string, float64, error) {
+	// D/C mark, YYMMDD date, currency, amount.
+	if len(value) < 11 {
+		return "", "", 0, fmt.Errorf("invalid balance %q", value)
+	}
+
+	date, err := time.Parse("060102", value[1:7])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid balance date %q", value[1:7])
+	}
+
+	amount, err := mt940Amount(value[10:])
+	if err != nil {
+		return "", "", 0, err
+	}
+	if value[0] == 'D' {
+		amount = -amount
+	}
+
+	return value[7:10], date.Format("2006-01-02"), amount, nil
+}
+
+func mt940Amount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+var sepaKeyword = regexp.MustCompile(`(EREF|KREF|MREF|CRED|DEBT|SVWZ|ABWA|ABWE|IBAN|BIC)\+`)
+
+func applyMT940Information(entry *Entry, value string) {
+	info := strings.ReplaceAll(value, "\n", "")
+
+	switch {
+	case len(info) > 3 && info[3] == '?':
+		applyMT940Subfields(entry, info)
+	case strings.HasPrefix(info, "/"):
+		applyMT940Slashed(entry, info)
+	default:
+		entry.Memo = cleanText(info)
+	}
+}
+
+// applyMT940Subfields handles the "?nn" layout, where ?20-?29 and ?60-?63
+// hold the purpose and ?32-?33 the counterparty name. The purpose may carry
+// SEPA keywords such as EREF+ and SVWZ+.
+func applyMT940Subfields(entry *Entry, info string) {
+	var purpose, name strings.Builder
+	for _, part := range strings.Split(info[3:], "?")[1:] {
+		if len(part) < 2 {
+			continue
+		}
+		code, text := part[:2], part[2:]
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			purpose.WriteString(text)
+		case code == "32" || code == "33":
+			name.WriteString(text)
+		}
+	}
+
+	if payee := cleanText(name.String()); payee != "" {
+		entry.Payee = payee
+	}
+
+	text := purpose.String()
+	locs := sepaKeyword.FindAllStringSubmatchIndex(text, -1)
+	if locs == nil {
+		entry.Memo = cleanText(text)
+		return
+	}
+
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		keyword, content := text[loc[2]:loc[3]], cleanText(text[loc[1]:end])
+
+		switch keyword {
+		case "EREF":
+			if content != "NOTPROVIDED" {
+				entry.Reference = content
+			}
+		case "SVWZ":
+			entry.Memo = content
+		}
+	}
+}
+
+// applyMT940Slashed handles the "/CODE/value" layout used by SEPA banks,
+// where /EREF/ is the end-to-end reference, /REMI/ the remittance
+// information and /NAME/ the counterparty name.
+func applyMT940Slashed(entry *Entry, info string) {
+	parts := strings.Split(info, "/")
+	for i := 1; i+1 < len(parts); i++ {
+		code, value := parts[i], strings.TrimSpace(parts[i+1])
+		switch code {
+		case "EREF":
+			if value != "NOTPROVIDED" {
+				entry.Reference = value
+			}
+			i++
+		case "REMI":
+			// REMI may contain structured sub-codes such as /USTD//text/.
+			rest := parts[i+1:]
+			for len(rest) > 0 && (rest[0] == "USTD" || rest[0] == "STRD" || rest[0] == "") {
+				rest = rest[1:]
+			}
+			if len(rest) > 0 {
+				entry.Memo = cleanText(rest[0])
+			}
+			i++
+		case "NAME":
+			entry.Payee = cleanText(value)
+			i++
+		}
+	}
+}
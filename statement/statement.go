@@ -0,0 +1,66 @@
+// Package statement parses bank statements in the ISO 20022 camt.053 and
+// SWIFT MT940 formats and imports their entries as transactions.
+package statement
+
+import (
+	"strings"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Entry is a single booked line on a statement. Amount is negative for
+// debits and positive for credits.
+type Entry struct {
+	BookingDate string // YYYY-MM-DD
+	ValueDate   string // YYYY-MM-DD
+	Amount      float64
+	Currency    string
+	// Payee is the counterparty: the creditor for debits and the debtor for
+	// credits.
+	Payee string
+	// Memo is the unstructured remittance information.
+	Memo string
+	// Reference is the end-to-end reference, or the bank's reference for the
+	// entry when no end-to-end reference is available.
+	Reference string
+}
+
+// Statement is a parsed account statement.
+type Statement struct {
+	ID             string
+	Account        string
+	Currency       string
+	OpeningBalance float64
+	ClosingBalance float64
+	// OpeningDate and ClosingDate are the dates of the balances, YYYY-MM-DD,
+	// when the statement includes them.
+	OpeningDate string
+	ClosingDate string
+	Entries     []*Entry
+}
+
+// Transaction converts the entry into a transaction for AddTransaction. The
+// reference is appended to the memo so it is kept in PocketSmith and can be
+// used to detect duplicates.
+func (e *Entry) Transaction() *pocketsmith.Transaction {
+	return &pocketsmith.Transaction{
+		Payee:       e.Payee,
+		Amount:      e.Amount,
+		Date:        e.BookingDate,
+		Memo:        e.memo(),
+		NeedsReview: true,
+	}
+}
+
+func (e *Entry) memo() string {
+	if e.Reference == "" || strings.Contains(e.Memo, e.Reference) {
+		return e.Memo
+	}
+	return strings.TrimSpace(e.Memo + " Ref: " + e.Reference)
+}
+
+// cleanText collapses the whitespace and line breaks banks put into
+// remittance fields.
+func cleanText(parts ...string) string {
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/txsync"
+)
+
+// FileStore is a Store that keeps each user's records as JSON files in a
+// directory named after the user ID. Transactions are loaded into memory on
+// first use and written back on every change.
+type FileStore struct {
+	dir string
+
+	mu           sync.Mutex
+	transactions map[int]map[int64]*pocketsmith.DetailedTransaction
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{
+		dir:          dir,
+		transactions: make(map[int]map[int64]*pocketsmith.DetailedTransaction),
+	}
+}
+
+const (
+	userFile                = "user.json"
+	accountsFile            = "accounts.json"
+	transactionAccountsFile = "transaction_accounts.json"
+	categoriesFile          = "categories.json"
+	transactionsFile        = "transactions.json"
+	cursorFile              = "cursor.json"
+)
+
+func (s *FileStore) SaveUser(user *pocketsmith.User) error {
+	return s.write(user.ID, userFile, user)
+}
+
+func (s *FileStore) User(userID int) (*pocketsmith.User, error) {
+	var user *pocketsmith.User
+	err := s.read(userID, userFile, &user)
+	return user, err
+}
+
+func (s *FileStore) SaveAccounts(userID int, accounts []*pocketsmith.Account) error {
+	return s.write(userID, accountsFile, accounts)
+}
+
+func (s *FileStore) Accounts(userID int) ([]*pocketsmith.Account, error) {
+	var accounts []*pocketsmith.Account
+	err := s.read(userID, accountsFile, &accounts)
+	return accounts, err
+}
+
+func (s *FileStore) SaveTransactionAccounts(userID int, transactionAccounts []*pocketsmith.TransactionAccount) error {
+	return s.write(userID, transactionAccountsFile, transactionAccounts)
+}
+
+func (s *FileStore) TransactionAccounts(userID int) ([]*pocketsmith.TransactionAccount, error) {
+	var transactionAccounts []*pocketsmith.TransactionAccount
+	err := s.read(userID, transactionAccountsFile, &transactionAccounts)
+	return transactionAccounts, err
+}
+
+func (s *FileStore) SaveCategories(userID int, categories []*pocketsmith.Category) error {
+	return s.write(userID, categoriesFile, categories)
+}
+
+func (s *FileStore) Categories(userID int) ([]*pocketsmith.Category, error) {
+	var categories []*pocketsmith.Category
+	err := s.read(userID, categoriesFile, &categories)
+	return categories, err
+}
+
+func (s *FileStore) PutTransactions(userID int, transactions []*pocketsmith.DetailedTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byID, err := s.loadTransactions(userID)
+	if err != nil {
+		return err
+	}
+
+	updated := maps.Clone(byID)
+	for _, tx := range transactions {
+		updated[tx.ID] = tx
+	}
+
+	return s.saveTransactions(userID, updated)
+}
+
+func (s *FileStore) DeleteTransactions(userID int, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byID, err := s.loadTransactions(userID)
+	if err != nil {
+		return err
+	}
+
+	updated := maps.Clone(byID)
+	for _, id := range ids {
+		delete(updated, id)
+	}
+
+	return s.saveTransactions(userID, updated)
+}
+
+func (s *FileStore) Transactions(userID int) ([]*pocketsmith.DetailedTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byID, err := s.loadTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*pocketsmith.DetailedTransaction, 0, len(byID))
+	for _, tx := range byID {
+		transactions = append(transactions, tx)
+	}
+	sortTransactions(transactions)
+
+	return transactions, nil
+}
+
+// Load implements txsync.CursorStore.
+func (s *FileStore) Load(userID int) (*txsync.Cursor, error) {
+	var cursor *txsync.Cursor
+	err := s.read(userID, cursorFile, &cursor)
+	return cursor, err
+}
+
+// Save implements txsync.CursorStore.
+func (s *FileStore) Save(cursor *txsync.Cursor) error {
+	return s.write(cursor.UserID, cursorFile, cursor)
+}
+
+// loadTransactions returns the cached transactions for the user, reading
+// them from disk the first time. s.mu must be held.
+func (s *FileStore) loadTransactions(userID int) (map[int64]*pocketsmith.DetailedTransaction, error) {
+	if byID, ok := s.transactions[userID]; ok {
+		return byID, nil
+	}
+
+	var transactions []*pocketsmith.DetailedTransaction
+	if err := s.read(userID, transactionsFile, &transactions); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*pocketsmith.DetailedTransaction, len(transactions))
+	for _, tx := range transactions {
+		byID[tx.ID] = tx
+	}
+	s.transactions[userID] = byID
+
+	return byID, nil
+}
+
+// saveTransactions writes byID to disk and only then makes it the cached
+// set, so a failed write leaves the cache matching the file. s.mu must be
+// held.
+func (s *FileStore) saveTransactions(userID int, byID map[int64]*pocketsmith.DetailedTransaction) error {
+	transactions := make([]*pocketsmith.DetailedTransaction, 0, len(byID))
+	for _, tx := range byID {
+		transactions = append(transactions, tx)
+	}
+	sortTransactions(transactions)
+
+	if err := s.write(userID, transactionsFile, transactions); err != nil {
+		return err
+	}
+
+	s.transactions[userID] = byID
+	return nil
+}
+
+// read decodes a user's file into v. A missing file leaves v untouched.
+func (s *FileStore) read(userID int, name string, v any) error {
+	data, err := os.ReadFile(filepath.Join(s.dir, strconv.Itoa(userID), name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// write encodes v into a user's file, replacing it atomically.
+func (s *FileStore) write(userID int, name string, v any) error {
+	dir := filepath.Join(s.dir, strconv.Itoa(userID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,245 @@
+// Package store keeps a local mirror of a user's PocketSmith data so it can
+// be queried without calling the API.
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/dvcrn/pocketsmith-go"
+	"github.com/dvcrn/pocketsmith-go/txsync"
+)
+
+// Store persists mirrored records. It also stores the sync cursor used to
+// keep transactions fresh.
+type Store interface {
+	txsync.CursorStore
+
+	SaveUser(user *pocketsmith.User) error
+	User(userID int) (*pocketsmith.User, error)
+
+	SaveAccounts(userID int, accounts []*pocketsmith.Account) error
+	Accounts(userID int) ([]*pocketsmith.Account, error)
+
+	SaveTransactionAccounts(userID int, transactionAccounts []*pocketsmith.TransactionAccount) error
+	TransactionAccounts(userID int) ([]*pocketsmith.TransactionAccount, error)
+
+	SaveCategories(userID int, categories []*pocketsmith.Category) error
+	Categories(userID int) ([]*pocketsmith.Category, error)
+
+	// PutTransactions inserts or replaces transactions by ID.
+	PutTransactions(userID int, transactions []*pocketsmith.DetailedTransaction) error
+	DeleteTransactions(userID int, ids []int64) error
+	// Transactions returns all of the user's transactions, sorted by date.
+	Transactions(userID int) ([]*pocketsmith.DetailedTransaction, error)
+}
+
+// Filter selects transactions in Query. The embedded TransactionFilter
+// covers the filters the API supports; the other fields are only available
+// locally.
+type Filter struct {
+	pocketsmith.TransactionFilter
+	CategoryIDs []int
+	Labels      []string
+	MinAmount   *float64
+	MaxAmount   *float64
+	Payee       *regexp.Regexp
+}
+
+type QueryOption func(*Filter)
+
+// WithListOptions applies the same options accepted by ListTransactions.
+func WithListOptions(opts ...pocketsmith.ListTransactionsOption) QueryOption {
+	return func(f *Filter) {
+		f.TransactionFilter = *pocketsmith.NewTransactionFilter(opts...)
+	}
+}
+
+// WithCategories matches transactions in any of the categories.
+func WithCategories(categoryIDs ...int) QueryOption {
+	return func(f *Filter) {
+		f.CategoryIDs = append(f.CategoryIDs, categoryIDs...)
+	}
+}
+
+// WithLabels matches transactions that have all of the labels.
+func WithLabels(labels ...string) QueryOption {
+	return func(f *Filter) {
+		f.Labels = append(f.Labels, labels...)
+	}
+}
+
+// WithAmountRange matches transactions with min <= amount <= max.
+func WithAmountRange(min, max float64) QueryOption {
+	return func(f *Filter) {
+		f.MinAmount = &min
+		f.MaxAmount = &max
+	}
+}
+
+// WithPayeeRegexp matches transactions whose payee matches re.
+func WithPayeeRegexp(re *regexp.Regexp) QueryOption {
+	return func(f *Filter) {
+		f.Payee = re
+	}
+}
+
+// Matches reports whether the transaction passes the filter.
+func (f *Filter) Matches(tx *pocketsmith.DetailedTransaction) bool {
+	if !f.TransactionFilter.Matches(tx) {
+		return false
+	}
+
+	if len(f.CategoryIDs) > 0 {
+		if tx.Category == nil {
+			return false
+		}
+		found := false
+		for _, id := range f.CategoryIDs {
+			if tx.Category.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, label := range f.Labels {
+		found := false
+		for _, txLabel := range tx.Labels {
+			if txLabel == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.MinAmount != nil && tx.Amount < *f.MinAmount {
+		return false
+	}
+	if f.MaxAmount != nil && tx.Amount > *f.MaxAmount {
+		return false
+	}
+	if f.Payee != nil && !f.Payee.MatchString(tx.Payee) {
+		return false
+	}
+
+	return true
+}
+
+// Query returns the user's stored transactions that match all of opts.
+func Query(s Store, userID int, opts ...QueryOption) ([]*pocketsmith.DetailedTransaction, error) {
+	filter := &Filter{}
+	for _, opt := range opts {
+		opt(filter)
+	}
+
+	transactions, err := s.Transactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []*pocketsmith.DetailedTransaction
+	for _, tx := range transactions {
+		if filter.Matches(tx) {
+			matching = append(matching, tx)
+		}
+	}
+
+	return matching, nil
+}
+
+// Mirror keeps a Store up to date with the API.
+type Mirror struct {
+	Client *pocketsmith.Client
+	Store  Store
+	Engine *txsync.Engine
+}
+
+// NewMirror returns a Mirror whose sync engine writes transaction changes to
+// the store and keeps its cursor there. Additional sinks receive the same
+// events.
+func NewMirror(client *pocketsmith.Client, s Store, sinks ...txsync.Sink) *Mirror {
+	m := &Mirror{Client: client, Store: s}
+	m.Engine = txsync.NewEngine(client, s, append([]txsync.Sink{txsync.SinkFunc(m.apply)}, sinks...)...)
+	return m
+}
+
+// Refresh re-fetches the user, accounts, transaction accounts and categories,
+// and syncs transactions updated since the last refresh.
+func (m *Mirror) Refresh(userID int) error {
+	user, err := m.Client.GetUser(userID)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+	if err := m.Store.SaveUser(user); err != nil {
+		return err
+	}
+
+	accounts, err := m.Client.ListAccounts(userID)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %w", err)
+	}
+	if err := m.Store.SaveAccounts(userID, accounts); err != nil {
+		return err
+	}
+
+	transactionAccounts, err := m.Client.ListTransactionAccounts(userID)
+	if err != nil {
+		return fmt.Errorf("error listing transaction accounts: %w", err)
+	}
+	if err := m.Store.SaveTransactionAccounts(userID, transactionAccounts); err != nil {
+		return err
+	}
+
+	categories, err := m.Client.ListCategories(userID)
+	if err != nil {
+		return fmt.Errorf("error listing categories: %w", err)
+	}
+	if err := m.Store.SaveCategories(userID, categories); err != nil {
+		return err
+	}
+
+	_, err = m.Engine.Sync(userID)
+	return err
+}
+
+func (m *Mirror) apply(events []txsync.Event) error {
+	put := make(map[int][]*pocketsmith.DetailedTransaction)
+	deleted := make(map[int][]int64)
+	for _, event := range events {
+		if event.Type == txsync.EventDeleted {
+			deleted[event.UserID] = append(deleted[event.UserID], event.Transaction.ID)
+		} else {
+			put[event.UserID] = append(put[event.UserID], event.Transaction)
+		}
+	}
+
+	for userID, transactions := range put {
+		if err := m.Store.PutTransactions(userID, transactions); err != nil {
+			return err
+		}
+	}
+	for userID, ids := range deleted {
+		if err := m.Store.DeleteTransactions(userID, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortTransactions(transactions []*pocketsmith.DetailedTransaction) {
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].Date != transactions[j].Date {
+			return transactions[i].Date < transactions[j].Date
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+}
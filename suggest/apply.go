@@ -0,0 +1,82 @@
+package suggest
+
+import (
+	"fmt"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// DefaultMaxCandidates is how many candidates Suggest keeps per transaction.
+const DefaultMaxCandidates = 3
+
+// Suggestion is the ranked categories for one transaction.
+type Suggestion struct {
+	Transaction *pocketsmith.DetailedTransaction `json:"transaction"`
+	Candidates  []Candidate                      `json:"candidates"`
+	// Applied is set when the top candidate was saved to the transaction.
+	Applied bool `json:"applied"`
+}
+
+// Best returns the top candidate, if any.
+func (s *Suggestion) Best() (Candidate, bool) {
+	if len(s.Candidates) == 0 {
+		return Candidate{}, false
+	}
+	return s.Candidates[0], true
+}
+
+// Train builds a model from the user's categorised transactions. opts are
+// passed to ListTransactionsInUser, for example to limit training to recent
+// history.
+func Train(client *pocketsmith.Client, userID int, opts ...pocketsmith.ListTransactionsOption) (*Model, error) {
+	m := NewModel()
+	err := client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		m.Train(page)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+	return m, nil
+}
+
+// Suggest ranks categories for each of the user's uncategorised
+// transactions. When threshold is above zero, the top candidate is saved to
+// transactions where its confidence reaches threshold; pass 0 to only
+// suggest.
+func Suggest(client *pocketsmith.Client, userID int, m *Model, threshold float64) ([]*Suggestion, error) {
+	var suggestions []*Suggestion
+	err := client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		for _, tx := range page {
+			candidates := m.Rank(tx)
+			if len(candidates) > DefaultMaxCandidates {
+				candidates = candidates[:DefaultMaxCandidates]
+			}
+			suggestions = append(suggestions, &Suggestion{Transaction: tx, Candidates: candidates})
+		}
+		return nil
+	}, pocketsmith.WithUncategorised(1))
+	if err != nil {
+		return nil, fmt.Errorf("error listing uncategorised transactions: %w", err)
+	}
+
+	if threshold <= 0 {
+		return suggestions, nil
+	}
+
+	for _, s := range suggestions {
+		best, ok := s.Best()
+		if !ok || best.Confidence < threshold {
+			continue
+		}
+
+		update := s.Transaction.Transaction()
+		update.CategoryID = pocketsmith.CategoryID(best.CategoryID)
+		if _, err := client.UpdateTransaction(s.Transaction.ID, update); err != nil {
+			return suggestions, fmt.Errorf("error updating transaction %d: %w", s.Transaction.ID, err)
+		}
+		s.Applied = true
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,66 @@
+package suggest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// amountBuckets are the upper bounds of the amount ranges used as features.
+var amountBuckets = []float64{5, 10, 20, 50, 100, 200, 500, 1000, 5000}
+
+// features returns the features a transaction is classified by: the tokens
+// of its payee, its amount range and direction, its transaction account and
+// its weekday.
+func features(tx *pocketsmith.DetailedTransaction) []string {
+	var result []string
+	for _, token := range tokens(tx.Payee) {
+		result = append(result, "payee:"+token)
+	}
+
+	direction := "in"
+	if tx.Amount < 0 {
+		direction = "out"
+	}
+	bucket := "max"
+	for _, bound := range amountBuckets {
+		if math.Abs(tx.Amount) < bound {
+			bucket = fmt.Sprint(bound)
+			break
+		}
+	}
+	result = append(result, "amount:"+direction+"<"+bucket)
+
+	if tx.TransactionAccount != nil {
+		result = append(result, fmt.Sprintf("account:%d", tx.TransactionAccount.ID))
+	}
+
+	if date, err := time.Parse("2006-01-02", tx.Date); err == nil {
+		result = append(result, "weekday:"+date.Weekday().String())
+	}
+
+	return result
+}
+
+// tokens splits a payee into lower case words of two or more letters,
+// dropping numbers such as store and reference numbers.
+func tokens(payee string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(payee), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, field := range fields {
+		if len(field) < 2 || seen[field] {
+			continue
+		}
+		seen[field] = true
+		result = append(result, field)
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+// Package suggest ranks categories for uncategorised transactions with a
+// naive Bayes model learnt from the user's categorised transactions.
+package suggest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+// Model counts how often each feature occurs in each category. It is safe to
+// encode as JSON to keep it between runs.
+type Model struct {
+	// Categories maps category IDs to their titles.
+	Categories map[int]string `json:"categories"`
+	// Documents is the number of transactions trained per category.
+	Documents map[int]int `json:"documents"`
+	// Features counts features per category.
+	Features map[int]map[string]int `json:"features"`
+	// Totals is the sum of Features per category.
+	Totals map[int]int `json:"totals"`
+	// Vocabulary is the number of distinct features seen.
+	Vocabulary int `json:"vocabulary"`
+
+	seen map[string]bool
+}
+
+func NewModel() *Model {
+	return &Model{
+		Categories: make(map[int]string),
+		Documents:  make(map[int]int),
+		Features:   make(map[int]map[string]int),
+		Totals:     make(map[int]int),
+	}
+}
+
+// Train adds the categorised transactions to the model. Uncategorised
+// transactions and transfers are skipped.
+func (m *Model) Train(transactions []*pocketsmith.DetailedTransaction) {
+	if m.seen == nil {
+		m.seen = make(map[string]bool)
+		for _, features := range m.Features {
+			for feature := range features {
+				m.seen[feature] = true
+			}
+		}
+	}
+
+	for _, tx := range transactions {
+		if tx.Category == nil || tx.IsTransfer || tx.Category.IsTransfer {
+			continue
+		}
+
+		id := tx.Category.ID
+		m.Categories[id] = tx.Category.Title
+		m.Documents[id]++
+		if m.Features[id] == nil {
+			m.Features[id] = make(map[string]int)
+		}
+		for _, feature := range features(tx) {
+			m.Features[id][feature]++
+			m.Totals[id]++
+			m.seen[feature] = true
+		}
+	}
+
+	m.Vocabulary = len(m.seen)
+}
+
+// Candidate is a category ranked for a transaction.
+type Candidate struct {
+	CategoryID int    `json:"category_id"`
+	Title      string `json:"title"`
+	// Confidence is the model's probability that the transaction belongs in
+	// the category, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+}
+
+// Rank returns the categories for the transaction, most likely first. It
+// returns nil when the model is empty.
+func (m *Model) Rank(tx *pocketsmith.DetailedTransaction) []Candidate {
+	documents := 0
+	for _, n := range m.Documents {
+		documents += n
+	}
+	if documents == 0 {
+		return nil
+	}
+
+	fs := features(tx)
+	scores := make(map[int]float64, len(m.Documents))
+	best := math.Inf(-1)
+	for id, n := range m.Documents {
+		score := math.Log(float64(n) / float64(documents))
+		denominator := float64(m.Totals[id] + m.Vocabulary + 1)
+		for _, feature := range fs {
+			score += math.Log(float64(m.Features[id][feature]+1) / denominator)
+		}
+		scores[id] = score
+		best = math.Max(best, score)
+	}
+
+	// Normalise the log scores into probabilities, shifting by the best
+	// score so the exponentials don't underflow.
+	var sum float64
+	for id, score := range scores {
+		scores[id] = math.Exp(score - best)
+		sum += scores[id]
+	}
+
+	candidates := make([]Candidate, 0, len(scores))
+	for id, score := range scores {
+		candidates = append(candidates, Candidate{CategoryID: id, Title: m.Categories[id], Confidence: score / sum})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].CategoryID < candidates[j].CategoryID
+	})
+
+	return candidates
+}
+
+// Save writes the model as JSON.
+func (m *Model) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// LoadModel reads a model written by Save.
+func LoadModel(r io.Reader) (*Model, error) {
+	m := NewModel()
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("error decoding model: %w", err)
+	}
+	return m, nil
+}
@@ -10,39 +10,66 @@ import (
 )
 
 type Transaction struct {
-	Payee        string     `json:"payee"`
-	Amount       float64    `json:"amount"`
-	Date         string     `json:"date"`
-	IsTransfer   bool       `json:"is_transfer"`
-	Labels       []string   `json:"labels,omitempty"`
-	CategoryID   CategoryID `json:"category_id,omitempty"`
-	Note         string     `json:"note,omitempty"`
-	Memo         string     `json:"memo,omitempty"`
-	ChequeNumber string     `json:"cheque_number,omitempty"`
-	NeedsReview  bool       `json:"needs_review"`
+	// ID is set on transactions returned by AddTransaction. It should be left
+	// zero in requests.
+	ID           int64                      `json:"id,omitempty"`
+	Payee        string                     `json:"payee"`
+	Amount       float64                    `json:"amount"`
+	Date         string                     `json:"date"`
+	IsTransfer   bool                       `json:"is_transfer"`
+	Labels       []string                   `json:"labels,omitempty"`
+	CategoryID   CategoryID                 `json:"category_id,omitempty"`
+	Note         string                     `json:"note,omitempty"`
+	Memo         string                     `json:"memo,omitempty"`
+	ChequeNumber string                     `json:"cheque_number,omitempty"`
+	NeedsReview  bool                       `json:"needs_review"`
+	Extra        map[string]json.RawMessage `json:"-"`
 }
 
 type DetailedTransaction struct {
-	ID                   int64               `json:"id"`
-	Payee                string              `json:"payee"`
-	OriginalPayee        string              `json:"original_payee"`
-	Date                 string              `json:"date"`
-	UploadSource         string              `json:"upload_source"`
-	Category             *Category           `json:"category"`
-	ClosingBalance       float64             `json:"closing_balance"`
-	ChequeNumber         string              `json:"cheque_number"`
-	Memo                 string              `json:"memo"`
-	Amount               float64             `json:"amount"`
-	AmountInBaseCurrency float64             `json:"amount_in_base_currency"`
-	Type                 string              `json:"type"`
-	IsTransfer           bool                `json:"is_transfer"`
-	NeedsReview          bool                `json:"needs_review"`
-	Status               string              `json:"status"`
-	Note                 string              `json:"note"`
-	Labels               []string            `json:"labels"`
-	TransactionAccount   *TransactionAccount `json:"transaction_account"`
-	CreatedAt            string              `json:"created_at"`
-	UpdatedAt            string              `json:"updated_at"`
+	ID                   int64                      `json:"id"`
+	Payee                string                     `json:"payee"`
+	OriginalPayee        string                     `json:"original_payee"`
+	Date                 string                     `json:"date"`
+	UploadSource         string                     `json:"upload_source"`
+	Category             *Category                  `json:"category"`
+	ClosingBalance       float64                    `json:"closing_balance"`
+	ChequeNumber         string                     `json:"cheque_number"`
+	Memo                 string                     `json:"memo"`
+	Amount               float64                    `json:"amount"`
+	AmountInBaseCurrency float64                    `json:"amount_in_base_currency"`
+	Type                 string                     `json:"type"`
+	IsTransfer           bool                       `json:"is_transfer"`
+	NeedsReview          bool                       `json:"needs_review"`
+	Status               string                     `json:"status"`
+	Note                 string                     `json:"note"`
+	Labels               []string                   `json:"labels"`
+	TransactionAccount   *TransactionAccount        `json:"transaction_account"`
+	CreatedAt            string                     `json:"created_at"`
+	UpdatedAt            string                     `json:"updated_at"`
+	Extra                map[string]json.RawMessage `json:"-"`
+}
+
+// Transaction returns the writable fields of the transaction, for example to
+// change some of them and pass the result to UpdateTransaction, which
+// replaces every field it is sent. The labels are copied, so appending to
+// them doesn't change t.
+func (t *DetailedTransaction) Transaction() *Transaction {
+	tx := &Transaction{
+		Payee:        t.Payee,
+		Amount:       t.Amount,
+		Date:         t.Date,
+		IsTransfer:   t.IsTransfer,
+		Labels:       append([]string(nil), t.Labels...),
+		Note:         t.Note,
+		Memo:         t.Memo,
+		ChequeNumber: t.ChequeNumber,
+		NeedsReview:  t.NeedsReview,
+	}
+	if t.Category != nil {
+		tx.CategoryID = CategoryID(t.Category.ID)
+	}
+	return tx
 }
 
 // AddTransaction creates a new transaction for the specified account.
@@ -209,6 +236,63 @@ func (c *Client) UpdateTransaction(transactionID int64, transaction *Transaction
 	return tx, nil
 }
 
+// ReplaceTransaction is like UpdateTransaction, but also sends the labels,
+// category, note, memo and cheque number when they are empty, so they are
+// cleared rather than left as they are. A zero CategoryID removes the
+// category.
+func (c *Client) ReplaceTransaction(transactionID int64, transaction *Transaction) (*DetailedTransaction, error) {
+	url := fmt.Sprintf("https://api.pocketsmith.com/v2/transactions/%d", transactionID)
+
+	payload := struct {
+		Payee        string     `json:"payee"`
+		Amount       float64    `json:"amount"`
+		Date         string     `json:"date"`
+		IsTransfer   bool       `json:"is_transfer"`
+		Labels       []string   `json:"labels"`
+		CategoryID   CategoryID `json:"category_id"`
+		Note         string     `json:"note"`
+		Memo         string     `json:"memo"`
+		ChequeNumber string     `json:"cheque_number"`
+		NeedsReview  bool       `json:"needs_review"`
+	}{
+		Payee:        transaction.Payee,
+		Amount:       transaction.Amount,
+		Date:         transaction.Date,
+		IsTransfer:   transaction.IsTransfer,
+		Labels:       transaction.Labels,
+		CategoryID:   transaction.CategoryID,
+		Note:         transaction.Note,
+		Memo:         transaction.Memo,
+		ChequeNumber: transaction.ChequeNumber,
+		NeedsReview:  transaction.NeedsReview,
+	}
+	if payload.Labels == nil {
+		payload.Labels = []string{}
+	}
+	if payload.CategoryID == 0 {
+		payload.CategoryID = CategoryIDNone
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("content-type", "application/json")
+
+	var tx *DetailedTransaction
+	if err := c.doAndDecode(req, &tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
 // SearchTransactionsByMemo searches for transactions by the memo field within a given date range.
 // It takes an accountID, a referenceNo string to search for in the memo field, and a transactionDate time.Time.
 // It returns a slice of matching Transaction pointers, or an error if the search fails.
@@ -334,6 +418,24 @@ func (c *Client) ListTransactionsInUser(userID int, opts ...ListTransactionsOpti
 	return c.listTransactions(url, opts...)
 }
 
+// WalkTransactionsInUser pages through all transactions across the user's
+// accounts, calling fn with each page. Paging starts at page 1 and stops at
+// the first empty page or when fn returns an error.
+func (c *Client) WalkTransactionsInUser(userID int, fn func([]*DetailedTransaction) error, opts ...ListTransactionsOption) error {
+	for page := 1; ; page++ {
+		transactions, err := c.ListTransactionsInUser(userID, append(opts, WithPage(page))...)
+		if err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+		if err := fn(transactions); err != nil {
+			return err
+		}
+	}
+}
+
 // ListTransactionsInAccount retrieves a list of transactions in an account.
 func (c *Client) ListTransactionsInAccount(accountID int, opts ...ListTransactionsOption) ([]*DetailedTransaction, error) {
 	url := fmt.Sprintf("https://api.pocketsmith.com/v2/accounts/%d/transactions", accountID)
@@ -392,3 +494,91 @@ func (c *Client) DeleteTransaction(transactionID int64) error {
 
 	return c.doAndDecode(req, nil)
 }
+
+// TransactionFilter holds the filters set by a list of ListTransactionsOption.
+// It lets transactions that were fetched earlier be filtered the same way the
+// API filters them.
+type TransactionFilter struct {
+	StartDate       string
+	EndDate         string
+	UpdatedSince    string
+	Uncategorised   bool
+	TransactionType string
+	NeedsReview     bool
+	Search          string
+}
+
+// NewTransactionFilter resolves opts into a TransactionFilter. WithPage is
+// ignored.
+func NewTransactionFilter(opts ...ListTransactionsOption) *TransactionFilter {
+	options := &listTransactionsOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &TransactionFilter{
+		StartDate:       options.startDate,
+		EndDate:         options.endDate,
+		UpdatedSince:    options.updatedSince,
+		Uncategorised:   options.uncategorised > 0,
+		TransactionType: options.transactionType,
+		NeedsReview:     options.needsReview > 0,
+		Search:          options.search,
+	}
+}
+
+// Matches reports whether the transaction passes the filter. Search is a
+// case-insensitive substring match against the payee, original payee, memo
+// and note.
+func (f *TransactionFilter) Matches(tx *DetailedTransaction) bool {
+	if f.StartDate != "" && tx.Date < f.StartDate {
+		return false
+	}
+	if f.EndDate != "" && tx.Date > f.EndDate {
+		return false
+	}
+	if f.UpdatedSince != "" && !updatedSince(tx.UpdatedAt, f.UpdatedSince) {
+		return false
+	}
+	if f.Uncategorised && tx.Category != nil {
+		return false
+	}
+	if f.TransactionType != "" && tx.Type != f.TransactionType {
+		return false
+	}
+	if f.NeedsReview && !tx.NeedsReview {
+		return false
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		found := false
+		for _, field := range []string{tx.Payee, tx.OriginalPayee, tx.Memo, tx.Note} {
+			if strings.Contains(strings.ToLower(field), search) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// updatedSince reports whether updatedAt is at or after since. Both may be
+// dates or RFC 3339 timestamps.
+func updatedSince(updatedAt, since string) bool {
+	parse := func(s string) (time.Time, error) {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", s)
+	}
+
+	a, errA := parse(updatedAt)
+	b, errB := parse(since)
+	if errA != nil || errB != nil {
+		return updatedAt >= since
+	}
+	return !a.Before(b)
+}
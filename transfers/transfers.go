@@ -0,0 +1,262 @@
+// Package transfers pairs up the two legs of transfers between a user's
+// transaction accounts and marks them as transfers.
+package transfers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+const (
+	// DefaultWindow is used when Matcher.Window is zero.
+	DefaultWindow = 4
+	// DefaultCurrencyTolerance is used when Matcher.CurrencyTolerance is
+	// zero.
+	DefaultCurrencyTolerance = 0.03
+)
+
+// Pair is a proposed transfer: money leaving one transaction account and
+// arriving in another.
+type Pair struct {
+	Out *pocketsmith.DetailedTransaction `json:"out"`
+	In  *pocketsmith.DetailedTransaction `json:"in"`
+	// Confidence ranges from 0 to 1.
+	Confidence float64 `json:"confidence"`
+	DaysApart  int     `json:"days_apart"`
+	// Difference is how far the converted amounts are apart, as a fraction
+	// of the outgoing amount. It is zero for same-currency transfers.
+	Difference float64 `json:"difference"`
+}
+
+// ConvertFunc converts amount from one currency to another at the rate on
+// date.
+type ConvertFunc func(amount float64, from, to, date string) (float64, error)
+
+// Matcher finds transfer pairs.
+type Matcher struct {
+	// Window is how many days apart the two legs may be.
+	Window int
+	// CurrencyTolerance is how far, as a fraction, converted amounts of a
+	// cross-currency transfer may differ, to allow for fees and rate
+	// differences.
+	CurrencyTolerance float64
+	// MinConfidence drops pairs below this confidence.
+	MinConfidence float64
+	// Convert converts amounts between currencies. When nil, cross-currency
+	// legs are compared by AmountInBaseCurrency.
+	Convert ConvertFunc
+}
+
+func NewMatcher() *Matcher {
+	return &Matcher{Window: DefaultWindow, CurrencyTolerance: DefaultCurrencyTolerance}
+}
+
+type leg struct {
+	tx       *pocketsmith.DetailedTransaction
+	date     time.Time
+	currency string
+}
+
+// Match proposes transfer pairs among transactions, which should span all of
+// the user's transaction accounts. Each transaction is used in at most one
+// pair; where several pairings are possible the most confident wins. Pairs
+// are sorted by the outgoing leg's date.
+func (m *Matcher) Match(transactions []*pocketsmith.DetailedTransaction) ([]*Pair, error) {
+	window := m.Window
+	if window == 0 {
+		window = DefaultWindow
+	}
+
+	var outs, ins []leg
+	for _, tx := range transactions {
+		if tx.Amount == 0 || tx.TransactionAccount == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: invalid date %q", tx.ID, tx.Date)
+		}
+		l := leg{tx: tx, date: date, currency: strings.ToLower(tx.TransactionAccount.CurrencyCode)}
+		if tx.Amount < 0 {
+			outs = append(outs, l)
+		} else {
+			ins = append(ins, l)
+		}
+	}
+	sort.Slice(ins, func(i, j int) bool { return ins[i].date.Before(ins[j].date) })
+
+	var candidates []*Pair
+	for _, out := range outs {
+		from := sort.Search(len(ins), func(i int) bool {
+			return !ins[i].date.Before(out.date.AddDate(0, 0, -window))
+		})
+		for _, in := range ins[from:] {
+			if in.date.After(out.date.AddDate(0, 0, window)) {
+				break
+			}
+			if in.tx.TransactionAccount.ID == out.tx.TransactionAccount.ID {
+				continue
+			}
+
+			pair, err := m.score(out, in, window)
+			if err != nil {
+				return nil, err
+			}
+			if pair != nil && pair.Confidence >= m.MinConfidence {
+				candidates = append(candidates, pair)
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].DaysApart < candidates[j].DaysApart
+	})
+
+	used := make(map[int64]bool)
+	var pairs []*Pair
+	for _, pair := range candidates {
+		if used[pair.Out.ID] || used[pair.In.ID] {
+			continue
+		}
+		used[pair.Out.ID], used[pair.In.ID] = true, true
+		pairs = append(pairs, pair)
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Out.Date != pairs[j].Out.Date {
+			return pairs[i].Out.Date < pairs[j].Out.Date
+		}
+		return pairs[i].Out.ID < pairs[j].Out.ID
+	})
+
+	return pairs, nil
+}
+
+// score returns the pair for two legs, or nil if their amounts don't match.
+// Confidence is mostly from how close the amounts and dates are, with the
+// rest from hints such as either leg already being marked as a transfer.
+func (m *Matcher) score(out, in leg, window int) (*Pair, error) {
+	tolerance := m.CurrencyTolerance
+	if tolerance == 0 {
+		tolerance = DefaultCurrencyTolerance
+	}
+
+	var difference float64
+	if out.currency == in.currency {
+		if math.Abs(out.tx.Amount+in.tx.Amount) >= 0.005 {
+			return nil, nil
+		}
+	} else {
+		sent, received := -out.tx.AmountInBaseCurrency, in.tx.AmountInBaseCurrency
+		if m.Convert != nil {
+			converted, err := m.Convert(-out.tx.Amount, out.currency, in.currency, in.tx.Date)
+			if err != nil {
+				return nil, fmt.Errorf("error converting %s to %s: %w", out.currency, in.currency, err)
+			}
+			sent, received = converted, in.tx.Amount
+		}
+		if sent == 0 {
+			return nil, nil
+		}
+		difference = math.Abs(sent-received) / math.Abs(sent)
+		if difference > tolerance {
+			return nil, nil
+		}
+	}
+
+	daysApart := int(math.Abs(in.date.Sub(out.date).Hours()) / 24)
+
+	amountScore := 1 - difference/tolerance
+	dateScore := 1 - float64(daysApart)/float64(window+1)
+
+	var hints float64
+	if out.tx.IsTransfer || in.tx.IsTransfer {
+		hints += 0.5
+	}
+	if isTransferCategory(out.tx) || isTransferCategory(in.tx) {
+		hints += 0.25
+	}
+	if mentions(out.tx, in.tx.TransactionAccount) || mentions(in.tx, out.tx.TransactionAccount) || mentionsTransfer(out.tx) || mentionsTransfer(in.tx) {
+		hints += 0.25
+	}
+
+	confidence := 0.5*amountScore + 0.3*dateScore + 0.2*hints
+	return &Pair{
+		Out:        out.tx,
+		In:         in.tx,
+		Confidence: math.Round(confidence*100) / 100,
+		DaysApart:  daysApart,
+		Difference: math.Round(difference*10000) / 10000,
+	}, nil
+}
+
+func isTransferCategory(tx *pocketsmith.DetailedTransaction) bool {
+	return tx.Category != nil && tx.Category.IsTransfer
+}
+
+// mentions reports whether the transaction's payee or memo names the other
+// transaction account or its number.
+func mentions(tx *pocketsmith.DetailedTransaction, other *pocketsmith.TransactionAccount) bool {
+	text := strings.ToLower(tx.Payee + " " + tx.OriginalPayee + " " + tx.Memo)
+	if name := strings.ToLower(other.Name); len(name) >= 3 && strings.Contains(text, name) {
+		return true
+	}
+	number := other.Number
+	if len(number) > 4 {
+		number = number[len(number)-4:]
+	}
+	return len(number) == 4 && strings.Contains(text, number)
+}
+
+func mentionsTransfer(tx *pocketsmith.DetailedTransaction) bool {
+	text := strings.ToLower(tx.Payee + " " + tx.OriginalPayee + " " + tx.Memo)
+	return strings.Contains(text, "transfer") || strings.Contains(text, "xfer")
+}
+
+// Find lists the user's transactions and matches them with the default
+// settings. opts are passed to ListTransactionsInUser, for example to limit
+// the date range.
+func Find(client *pocketsmith.Client, userID int, opts ...pocketsmith.ListTransactionsOption) ([]*Pair, error) {
+	var transactions []*pocketsmith.DetailedTransaction
+	err := client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		transactions = append(transactions, page...)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return NewMatcher().Match(transactions)
+}
+
+// Mark updates both legs of each pair to be transfers in the given category,
+// which should be a transfer category. Legs that are already marked are left
+// alone. It returns the number of transactions updated.
+func Mark(client *pocketsmith.Client, pairs []*Pair, categoryID int) (int, error) {
+	updated := 0
+	for _, pair := range pairs {
+		for _, tx := range []*pocketsmith.DetailedTransaction{pair.Out, pair.In} {
+			if tx.IsTransfer && tx.Category != nil && tx.Category.ID == categoryID {
+				continue
+			}
+
+			update := tx.Transaction()
+			update.IsTransfer = true
+			update.CategoryID = pocketsmith.CategoryID(categoryID)
+			if _, err := client.UpdateTransaction(tx.ID, update); err != nil {
+				return updated, fmt.Errorf("error updating transaction %d: %w", tx.ID, err)
+			}
+			updated++
+		}
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,92 @@
+package txsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cursor is the sync state for a user.
+type Cursor struct {
+	UserID int `json:"user_id"`
+	// UpdatedSince is the start time of the last run, passed as updated_since
+	// on the next one.
+	UpdatedSince string `json:"updated_since"`
+	LastFullSync string `json:"last_full_sync"`
+	// Known maps the ID of every transaction seen so far to its updated_at,
+	// to tell created and updated transactions apart and to detect deletions.
+	Known map[int64]string `json:"known"`
+}
+
+func (c *Cursor) needsFullSync(now time.Time, interval time.Duration) bool {
+	if c.UpdatedSince == "" || c.LastFullSync == "" {
+		return true
+	}
+
+	last, err := time.Parse(time.RFC3339, c.LastFullSync)
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(last) >= interval
+}
+
+// CursorStore persists cursors. Load returns nil without an error when there
+// is no cursor for the user yet.
+type CursorStore interface {
+	Load(userID int) (*Cursor, error)
+	Save(cursor *Cursor) error
+}
+
+// FileCursorStore keeps one JSON file per user in Dir.
+type FileCursorStore struct {
+	Dir string
+}
+
+func NewFileCursorStore(dir string) *FileCursorStore {
+	return &FileCursorStore{Dir: dir}
+}
+
+func (s *FileCursorStore) path(userID int) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("cursor-%d.json", userID))
+}
+
+func (s *FileCursorStore) Load(userID int) (*Cursor, error) {
+	data, err := os.ReadFile(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+// Save writes the cursor to a temporary file and renames it into place, so a
+// crash never leaves a half-written cursor behind.
+func (s *FileCursorStore) Save(cursor *Cursor) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path(cursor.UserID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path(cursor.UserID))
+}
@@ -0,0 +1,32 @@
+package txsync
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ChannelSink sends each event to a channel. It blocks until the events are
+// received.
+type ChannelSink chan<- Event
+
+func (c ChannelSink) Handle(events []Event) error {
+	for _, event := range events {
+		c <- event
+	}
+	return nil
+}
+
+// JSONLinesSink writes each event as a JSON object on its own line.
+type JSONLinesSink struct {
+	W io.Writer
+}
+
+func (s *JSONLinesSink) Handle(events []Event) error {
+	encoder := json.NewEncoder(s.W)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Package txsync incrementally syncs a user's transactions and emits a feed
+// of created, updated and deleted transactions.
+//
+// Each run only fetches transactions updated since the previous run, using
+// the updated_since filter. The API doesn't report deletions, so every
+// FullSyncInterval the engine lists all transactions instead and reports the
+// ones that have disappeared.
+package txsync
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dvcrn/pocketsmith-go"
+)
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// Event is a change to a transaction. For deleted transactions only the ID of
+// Transaction is set, as the transaction can no longer be fetched.
+type Event struct {
+	Type        EventType                        `json:"type"`
+	UserID      int                              `json:"user_id"`
+	Transaction *pocketsmith.DetailedTransaction `json:"transaction"`
+}
+
+// Sink receives the events of a sync run. If a sink returns an error the
+// cursor isn't advanced, so the same events are delivered again on the next
+// run.
+type Sink interface {
+	Handle(events []Event) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(events []Event) error
+
+func (f SinkFunc) Handle(events []Event) error {
+	return f(events)
+}
+
+// DefaultFullSyncInterval is used when Engine.FullSyncInterval is zero.
+const DefaultFullSyncInterval = 24 * time.Hour
+
+// Engine runs syncs for users, keeping a cursor for each in Store.
+type Engine struct {
+	Client *pocketsmith.Client
+	Store  CursorStore
+	Sinks  []Sink
+	// FullSyncInterval is how often all transactions are listed to detect
+	// deletions.
+	FullSyncInterval time.Duration
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewEngine(client *pocketsmith.Client, store CursorStore, sinks ...Sink) *Engine {
+	return &Engine{Client: client, Store: store, Sinks: sinks}
+}
+
+// Result summarises a sync run.
+type Result struct {
+	Full   bool
+	Events []Event
+}
+
+// Sync fetches changes for the user since the last run, delivers them to the
+// sinks and advances the cursor.
+func (e *Engine) Sync(userID int) (*Result, error) {
+	cursor, err := e.Store.Load(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading cursor: %w", err)
+	}
+	if cursor == nil {
+		cursor = &Cursor{UserID: userID}
+	}
+	if cursor.Known == nil {
+		cursor.Known = make(map[int64]string)
+	}
+
+	now := e.now()
+	full := cursor.needsFullSync(now, e.fullSyncInterval())
+
+	var opts []pocketsmith.ListTransactionsOption
+	if !full {
+		opts = append(opts, pocketsmith.WithUpdatedSince(cursor.UpdatedSince))
+	}
+
+	result := &Result{Full: full}
+	seen := make(map[int64]bool)
+	err = e.Client.WalkTransactionsInUser(userID, func(page []*pocketsmith.DetailedTransaction) error {
+		for _, tx := range page {
+			seen[tx.ID] = true
+
+			updatedAt, known := cursor.Known[tx.ID]
+			switch {
+			case !known:
+				result.Events = append(result.Events, Event{Type: EventCreated, UserID: userID, Transaction: tx})
+			case updatedAt != tx.UpdatedAt:
+				result.Events = append(result.Events, Event{Type: EventUpdated, UserID: userID, Transaction: tx})
+			}
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	if full {
+		var deleted []int64
+		for id := range cursor.Known {
+			if !seen[id] {
+				deleted = append(deleted, id)
+			}
+		}
+		sort.Slice(deleted, func(i, j int) bool { return deleted[i] < deleted[j] })
+
+		for _, id := range deleted {
+			result.Events = append(result.Events, Event{
+				Type:        EventDeleted,
+				UserID:      userID,
+				Transaction: &pocketsmith.DetailedTransaction{ID: id},
+			})
+		}
+	}
+
+	if len(result.Events) > 0 {
+		for _, sink := range e.Sinks {
+			if err := sink.Handle(result.Events); err != nil {
+				return result, fmt.Errorf("error delivering events: %w", err)
+			}
+		}
+	}
+
+	for _, event := range result.Events {
+		if event.Type == EventDeleted {
+			delete(cursor.Known, event.Transaction.ID)
+		} else {
+			cursor.Known[event.Transaction.ID] = event.Transaction.UpdatedAt
+		}
+	}
+	cursor.UpdatedSince = now.UTC().Format(time.RFC3339)
+	if full {
+		cursor.LastFullSync = cursor.UpdatedSince
+	}
+
+	if err := e.Store.Save(cursor); err != nil {
+		return result, fmt.Errorf("error saving cursor: %w", err)
+	}
+
+	return result, nil
+}
+
+func (e *Engine) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+func (e *Engine) fullSyncInterval() time.Duration {
+	if e.FullSyncInterval > 0 {
+		return e.FullSyncInterval
+	}
+	return DefaultFullSyncInterval
+}
@@ -32,20 +32,21 @@ type User struct {
 	// TellAFriendAccess and TellAFriendCode are returned by the API but are
 	// undocumented, and were null for every account inspected. They are kept
 	// raw so their value is preserved without guessing at their type.
-	TellAFriendAccess        json.RawMessage `json:"tell_a_friend_access"`
-	TellAFriendCode          json.RawMessage `json:"tell_a_friend_code"`
-	ForecastLastUpdatedAt    string          `json:"forecast_last_updated_at"`
-	ForecastLastAccessedAt   string          `json:"forecast_last_accessed_at"`
-	ForecastStartDate        string          `json:"forecast_start_date"`
-	ForecastEndDate          string          `json:"forecast_end_date"`
-	ForecastDeferRecalculate bool            `json:"forecast_defer_recalculate"`
-	ForecastNeedsRecalculate bool            `json:"forecast_needs_recalculate"`
-	FeedHistoryStartsFrom    string          `json:"feed_history_starts_from"`
-	FeedHistoryTouched       bool            `json:"feed_history_touched"`
-	LastLoggedInAt           string          `json:"last_logged_in_at"`
-	LastActivityAt           string          `json:"last_activity_at"`
-	CreatedAt                string          `json:"created_at"`
-	UpdatedAt                string          `json:"updated_at"`
+	TellAFriendAccess        json.RawMessage            `json:"tell_a_friend_access"`
+	TellAFriendCode          json.RawMessage            `json:"tell_a_friend_code"`
+	ForecastLastUpdatedAt    string                     `json:"forecast_last_updated_at"`
+	ForecastLastAccessedAt   string                     `json:"forecast_last_accessed_at"`
+	ForecastStartDate        string                     `json:"forecast_start_date"`
+	ForecastEndDate          string                     `json:"forecast_end_date"`
+	ForecastDeferRecalculate bool                       `json:"forecast_defer_recalculate"`
+	ForecastNeedsRecalculate bool                       `json:"forecast_needs_recalculate"`
+	FeedHistoryStartsFrom    string                     `json:"feed_history_starts_from"`
+	FeedHistoryTouched       bool                       `json:"feed_history_touched"`
+	LastLoggedInAt           string                     `json:"last_logged_in_at"`
+	LastActivityAt           string                     `json:"last_activity_at"`
+	CreatedAt                string                     `json:"created_at"`
+	UpdatedAt                string                     `json:"updated_at"`
+	Extra                    map[string]json.RawMessage `json:"-"`
 }
 
 // Label is a transaction label belonging to a user.
@@ -53,10 +54,11 @@ type Label string
 
 // SavedSearch is a saved transaction search belonging to a user.
 type SavedSearch struct {
-	ID        int    `json:"id"`
-	Title     string `json:"title"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int                        `json:"id"`
+	Title     string                     `json:"title"`
+	CreatedAt string                     `json:"created_at"`
+	UpdatedAt string                     `json:"updated_at"`
+	Extra     map[string]json.RawMessage `json:"-"`
 }
 
 // UpdateUser holds the fields accepted by PUT /users/{id}. Fields left empty
@@ -78,18 +80,9 @@ func (c *Client) GetCurrentUser() (*User, error) {
 	}
 
 	req.Header.Add("accept", "application/json")
-	req.Header.Add("X-Developer-Key", c.token)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 
 	var user User
-	err = json.NewDecoder(resp.Body).Decode(&user)
-	if err != nil {
+	if err := c.doAndDecode(req, &user); err != nil {
 		return nil, err
 	}
 
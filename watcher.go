@@ -0,0 +1,328 @@
+package pocketsmith
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type WatchEventType string
+
+const (
+	WatchEventTransactionCreated    WatchEventType = "transaction.created"
+	WatchEventTransactionUpdated    WatchEventType = "transaction.updated"
+	WatchEventAccountBalanceChanged WatchEventType = "account.balance_changed"
+	WatchEventAttachmentUploaded    WatchEventType = "attachment.uploaded"
+)
+
+// WatchEvent is a change detected by a Watcher. Only the field matching Type
+// is set.
+type WatchEvent struct {
+	Type            WatchEventType       `json:"type"`
+	UserID          int                  `json:"user_id"`
+	DetectedAt      string               `json:"detected_at"`
+	Transaction     *DetailedTransaction `json:"transaction,omitempty"`
+	Account         *Account             `json:"account,omitempty"`
+	PreviousBalance float64              `json:"previous_balance,omitempty"`
+	Attachment      *Attachment          `json:"attachment,omitempty"`
+}
+
+// WebhookTarget is an HTTP endpoint events are posted to. When Secret is set,
+// each request carries an X-Pocketsmith-Signature header of the form
+// "sha256=<hex>", the HMAC-SHA256 of the body keyed with Secret.
+type WebhookTarget struct {
+	URL    string
+	Secret string
+}
+
+type WatcherOption func(*watcherOptions)
+
+type watcherOptions struct {
+	interval   time.Duration
+	events     chan<- WatchEvent
+	webhooks   []WebhookTarget
+	retries    int
+	retryDelay time.Duration
+	httpClient *http.Client
+	onError    func(error)
+}
+
+// DefaultWebhookTimeout limits each webhook delivery attempt unless
+// WithWebhookClient is used.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WithWatchInterval sets how often the watcher polls. The default is one
+// minute.
+func WithWatchInterval(interval time.Duration) WatcherOption {
+	return func(o *watcherOptions) {
+		o.interval = interval
+	}
+}
+
+// WithEventChannel delivers events to ch. Sends block until the event is
+// received or the watcher is stopped.
+func WithEventChannel(ch chan<- WatchEvent) WatcherOption {
+	return func(o *watcherOptions) {
+		o.events = ch
+	}
+}
+
+// WithWebhook posts every event as JSON to url, signed with secret.
+func WithWebhook(url string, secret string) WatcherOption {
+	return func(o *watcherOptions) {
+		o.webhooks = append(o.webhooks, WebhookTarget{URL: url, Secret: secret})
+	}
+}
+
+// WithWebhookRetries sets how many times a failed webhook delivery is retried,
+// doubling delay after each attempt. The default is 3 retries starting at one
+// second.
+func WithWebhookRetries(retries int, delay time.Duration) WatcherOption {
+	return func(o *watcherOptions) {
+		o.retries = retries
+		o.retryDelay = delay
+	}
+}
+
+// WithWebhookClient sets the http.Client webhooks are posted with. The
+// default gives up on an attempt after DefaultWebhookTimeout, so a target
+// that never responds can't stall polling.
+func WithWebhookClient(client *http.Client) WatcherOption {
+	return func(o *watcherOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithWatchErrorHandler is called with errors from polling and webhook
+// delivery. The watcher keeps running after an error.
+func WithWatchErrorHandler(onError func(error)) WatcherOption {
+	return func(o *watcherOptions) {
+		o.onError = onError
+	}
+}
+
+// Watcher emulates webhooks by polling a user's transactions, account
+// balances and unassigned attachments and reporting what changed.
+type Watcher struct {
+	client  *Client
+	userID  int
+	options *watcherOptions
+
+	primed       bool
+	updatedSince time.Time
+	balances     map[int]float64
+	attachments  map[int64]bool
+}
+
+// NewWatcher returns a Watcher for the user. Call Run to start polling.
+func (c *Client) NewWatcher(userID int, opts ...WatcherOption) *Watcher {
+	options := &watcherOptions{
+		interval:   time.Minute,
+		retries:    3,
+		retryDelay: time.Second,
+		httpClient: &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &Watcher{client: c, userID: userID, options: options}
+}
+
+// Run polls until ctx is done. The first poll only records the current state;
+// events are reported from the second poll onwards.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.options.interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Poll()
+		if err != nil {
+			w.reportError(err)
+		}
+
+		for _, event := range events {
+			if err := w.deliver(ctx, event); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				w.reportError(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks for changes once and returns the events without delivering
+// them.
+func (w *Watcher) Poll() ([]WatchEvent, error) {
+	now := time.Now().UTC()
+	detectedAt := now.Format(time.RFC3339)
+
+	accounts, err := w.client.ListAccounts(w.userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %w", err)
+	}
+
+	attachments, err := w.client.ListAttachments(w.userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error listing attachments: %w", err)
+	}
+
+	var transactions []*DetailedTransaction
+	if w.primed {
+		err := w.client.WalkTransactionsInUser(w.userID, func(page []*DetailedTransaction) error {
+			transactions = append(transactions, page...)
+			return nil
+		}, WithUpdatedSince(w.updatedSince.Format(time.RFC3339)))
+		if err != nil {
+			return nil, fmt.Errorf("error listing transactions: %w", err)
+		}
+	}
+
+	balances := make(map[int]float64, len(accounts))
+	for _, account := range accounts {
+		balances[account.ID] = account.CurrentBalance
+	}
+	unassigned := make(map[int64]bool, len(attachments))
+	for _, attachment := range attachments {
+		unassigned[attachment.ID] = true
+	}
+
+	var events []WatchEvent
+	if w.primed {
+		for _, tx := range transactions {
+			eventType := WatchEventTransactionUpdated
+			if updatedSince(tx.CreatedAt, w.updatedSince.Format(time.RFC3339)) {
+				eventType = WatchEventTransactionCreated
+			}
+			events = append(events, WatchEvent{Type: eventType, UserID: w.userID, DetectedAt: detectedAt, Transaction: tx})
+		}
+
+		for _, account := range accounts {
+			previous, ok := w.balances[account.ID]
+			if ok && previous != account.CurrentBalance {
+				events = append(events, WatchEvent{
+					Type:            WatchEventAccountBalanceChanged,
+					UserID:          w.userID,
+					DetectedAt:      detectedAt,
+					Account:         account,
+					PreviousBalance: previous,
+				})
+			}
+		}
+
+		for _, attachment := range attachments {
+			if !w.attachments[attachment.ID] {
+				events = append(events, WatchEvent{Type: WatchEventAttachmentUploaded, UserID: w.userID, DetectedAt: detectedAt, Attachment: attachment})
+			}
+		}
+	}
+
+	w.primed = true
+	w.updatedSince = now
+	w.balances = balances
+	w.attachments = unassigned
+
+	return events, nil
+}
+
+func (w *Watcher) deliver(ctx context.Context, event WatchEvent) error {
+	if w.options.events != nil {
+		select {
+		case w.options.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if len(w.options.webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range w.options.webhooks {
+		if err := w.post(ctx, target, event.Type, body); err != nil {
+			w.reportError(err)
+		}
+	}
+
+	return nil
+}
+
+// post delivers a webhook, retrying failed requests and 5xx responses.
+func (w *Watcher) post(ctx context.Context, target WebhookTarget, eventType WatchEventType, body []byte) error {
+	delay := w.options.retryDelay
+
+	var lastErr error
+	for attempt := 0; attempt <= w.options.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", target.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Add("content-type", "application/json")
+		req.Header.Add("X-Pocketsmith-Event", string(eventType))
+		if target.Secret != "" {
+			req.Header.Add("X-Pocketsmith-Signature", "sha256="+SignWebhook(target.Secret, body))
+		}
+
+		resp, err := w.options.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("webhook %s returned %s", target.URL, resp.Status)
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %s returned %s", target.URL, resp.Status)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("webhook %s failed after %d attempts: %w", target.URL, w.options.retries+1, lastErr)
+}
+
+func (w *Watcher) reportError(err error) {
+	if w.options.onError != nil {
+		w.options.onError(err)
+	}
+}
+
+// SignWebhook returns the hex-encoded HMAC-SHA256 of body keyed with secret,
+// as sent in the X-Pocketsmith-Signature header. Receivers can use it to
+// verify a delivery.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}